@@ -0,0 +1,219 @@
+package coinpayments
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	decimalPattern  = regexp.MustCompile(`^[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
+	integerPattern  = regexp.MustCompile(`^[-+]?\d+$`)
+	satoshisPerCoin = big.NewRat(100000000, 1)
+)
+
+//Amount is an exact decimal amount of a coin or currency. The zero value is 0
+type Amount struct {
+	rat *big.Rat
+}
+
+//ParseAmount parses a decimal string such as "0.00125000" into an Amount
+func ParseAmount(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return Amount{}, fmt.Errorf("coinpayments: invalid amount %q", s)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Amount{}, fmt.Errorf("coinpayments: invalid amount %q", s)
+	}
+	return Amount{rat: r}, nil
+}
+
+//ParseSatoshis parses an integer string counting 1e-8 units, such as the 'amounti' IPN field, into an Amount
+func ParseSatoshis(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if !integerPattern.MatchString(s) {
+		return Amount{}, fmt.Errorf("coinpayments: invalid satoshi amount %q", s)
+	}
+
+	i, ok := new(big.Int).SetString(strings.TrimPrefix(s, "+"), 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("coinpayments: invalid satoshi amount %q", s)
+	}
+	r := new(big.Rat).SetInt(i)
+	return Amount{rat: r.Quo(r, satoshisPerCoin)}, nil
+}
+
+//MustParseAmount is like ParseAmount but panics if the string cannot be parsed
+func MustParseAmount(s string) Amount {
+	a, err := ParseAmount(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+//AmountFromSatoshis returns the Amount of a count of 1e-8 units
+func AmountFromSatoshis(satoshis int64) Amount {
+	r := new(big.Rat).SetInt64(satoshis)
+	return Amount{rat: r.Quo(r, satoshisPerCoin)}
+}
+
+func (a Amount) value() *big.Rat {
+	if a.rat == nil {
+		return new(big.Rat)
+	}
+	return a.rat
+}
+
+//Add returns a + b
+func (a Amount) Add(b Amount) Amount {
+	return Amount{rat: new(big.Rat).Add(a.value(), b.value())}
+}
+
+//Sub returns a - b
+func (a Amount) Sub(b Amount) Amount {
+	return Amount{rat: new(big.Rat).Sub(a.value(), b.value())}
+}
+
+//Mul returns a * b
+func (a Amount) Mul(b Amount) Amount {
+	return Amount{rat: new(big.Rat).Mul(a.value(), b.value())}
+}
+
+//Neg returns -a
+func (a Amount) Neg() Amount {
+	return Amount{rat: new(big.Rat).Neg(a.value())}
+}
+
+//Abs returns |a|
+func (a Amount) Abs() Amount {
+	return Amount{rat: new(big.Rat).Abs(a.value())}
+}
+
+//Round returns a rounded to the number of decimal places, with halves rounded away from zero
+func (a Amount) Round(places int) Amount {
+	rounded, _ := ParseAmount(a.StringFixed(places))
+	return rounded
+}
+
+//Cmp returns -1 if a < b, 0 if a == b and 1 if a > b
+func (a Amount) Cmp(b Amount) int {
+	return a.value().Cmp(b.value())
+}
+
+//Equal reports whether a == b
+func (a Amount) Equal(b Amount) bool {
+	return a.Cmp(b) == 0
+}
+
+//LessThan reports whether a < b
+func (a Amount) LessThan(b Amount) bool {
+	return a.Cmp(b) < 0
+}
+
+//GreaterThan reports whether a > b
+func (a Amount) GreaterThan(b Amount) bool {
+	return a.Cmp(b) > 0
+}
+
+//Sign returns -1 if a < 0, 0 if a == 0 and 1 if a > 0
+func (a Amount) Sign() int {
+	return a.value().Sign()
+}
+
+//IsZero reports whether a == 0
+func (a Amount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+//Satoshis returns a as a count of 1e-8 units, truncating anything smaller
+func (a Amount) Satoshis() int64 {
+	r := new(big.Rat).Mul(a.value(), satoshisPerCoin)
+	return new(big.Int).Quo(r.Num(), r.Denom()).Int64()
+}
+
+//String returns a as an exact decimal string with no trailing zeros
+func (a Amount) String() string {
+	r := a.value()
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	places := 0
+	x := new(big.Rat).Set(r)
+	ten := big.NewRat(10, 1)
+	for !x.IsInt() && places < 1024 {
+		x.Mul(x, ten)
+		places++
+	}
+	return r.FloatString(places)
+}
+
+//StringFixed returns a as a decimal string with the number of decimal places, with halves rounded away from zero
+func (a Amount) StringFixed(places int) string {
+	return a.value().FloatString(places)
+}
+
+//MarshalText encodes a as its exact decimal string
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+//UnmarshalText decodes a decimal string, treating an empty string as 0
+func (a *Amount) UnmarshalText(text []byte) error {
+	if len(strings.TrimSpace(string(text))) == 0 {
+		*a = Amount{}
+		return nil
+	}
+
+	parsed, err := ParseAmount(string(text))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+//MarshalJSON encodes a as a json string holding its exact decimal
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(a.String())), nil
+}
+
+//UnmarshalJSON decodes a json string or number without passing through a float
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return fmt.Errorf("coinpayments: invalid amount %v", s)
+		}
+		s = unquoted
+	}
+	return a.UnmarshalText([]byte(s))
+}
+
+//Satoshis is an amount the api sends as an integer count of 1e-8 units
+type Satoshis int64
+
+//Amount returns the satoshis as an Amount
+func (s Satoshis) Amount() Amount {
+	return AmountFromSatoshis(int64(s))
+}
+
+//UnmarshalJSON decodes a json number or string, treating null and an empty string as 0
+func (s *Satoshis) UnmarshalJSON(data []byte) error {
+	var i FlexInt
+	if err := i.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	*s = Satoshis(i)
+	return nil
+}
@@ -0,0 +1,193 @@
+package coinpayments
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "0.00125000", want: "0.00125"},
+		{in: "10", want: "10"},
+		{in: " 1.5 ", want: "1.5"},
+		{in: "+2.50", want: "2.5"},
+		{in: "-0.1", want: "-0.1"},
+		{in: ".5", want: "0.5"},
+		{in: "5.", want: "5"},
+		{in: "1e-8", want: "0.00000001"},
+		{in: "0.1", want: "0.1"},
+		{in: "123456789.123456789", want: "123456789.123456789"},
+		{in: "", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "1/3", wantErr: true},
+		{in: "1,5", wantErr: true},
+		{in: "NaN", wantErr: true},
+		{in: "Inf", wantErr: true},
+	}
+
+	for _, test := range tests {
+		a, err := ParseAmount(test.in)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParseAmount(%q) = %v, want an error", test.in, a)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseAmount(%q) returned error %v", test.in, err)
+			continue
+		}
+		if got := a.String(); got != test.want {
+			t.Errorf("ParseAmount(%q) = %v, want %v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestParseSatoshis(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "100000", want: "0.001"},
+		{in: "1", want: "0.00000001"},
+		{in: "+250000000", want: "2.5"},
+		{in: "-500", want: "-0.000005"},
+		{in: "0", want: "0"},
+		{in: "1.5", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, test := range tests {
+		a, err := ParseSatoshis(test.in)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParseSatoshis(%q) = %v, want an error", test.in, a)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSatoshis(%q) returned error %v", test.in, err)
+			continue
+		}
+		if got := a.String(); got != test.want {
+			t.Errorf("ParseSatoshis(%q) = %v, want %v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	a, b := MustParseAmount("0.1"), MustParseAmount("0.2")
+
+	if got := a.Add(b).String(); got != "0.3" {
+		t.Errorf("0.1 + 0.2 = %v, want 0.3", got)
+	}
+	if got := a.Sub(b).String(); got != "-0.1" {
+		t.Errorf("0.1 - 0.2 = %v, want -0.1", got)
+	}
+	if got := a.Mul(b).String(); got != "0.02" {
+		t.Errorf("0.1 * 0.2 = %v, want 0.02", got)
+	}
+	if got := a.Sub(b).Abs().String(); got != "0.1" {
+		t.Errorf("|0.1 - 0.2| = %v, want 0.1", got)
+	}
+	if got := a.Neg().String(); got != "-0.1" {
+		t.Errorf("-0.1 = %v", got)
+	}
+	if !a.LessThan(b) || a.GreaterThan(b) || a.Equal(b) || a.Cmp(b) != -1 {
+		t.Error("0.1 should compare less than 0.2")
+	}
+	if !a.Add(b).Equal(MustParseAmount("0.30000000")) {
+		t.Error("0.1 + 0.2 should equal 0.30000000")
+	}
+
+	var zero Amount
+	if !zero.IsZero() || zero.Sign() != 0 || zero.String() != "0" {
+		t.Errorf("zero value = %v, want 0", zero)
+	}
+	if got := zero.Add(a).String(); got != "0.1" {
+		t.Errorf("0 + 0.1 = %v, want 0.1", got)
+	}
+}
+
+func TestAmountRounding(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int
+		want   string
+		fixed  string
+	}{
+		{in: "1.005", places: 2, want: "1.01", fixed: "1.01"},
+		{in: "1.004", places: 2, want: "1", fixed: "1.00"},
+		{in: "-1.005", places: 2, want: "-1.01", fixed: "-1.01"},
+		{in: "0.123456789", places: 8, want: "0.12345679", fixed: "0.12345679"},
+		{in: "2.5", places: 0, want: "3", fixed: "3"},
+		{in: "10", places: 8, want: "10", fixed: "10.00000000"},
+	}
+
+	for _, test := range tests {
+		a := MustParseAmount(test.in)
+		if got := a.Round(test.places).String(); got != test.want {
+			t.Errorf("%v.Round(%v) = %v, want %v", test.in, test.places, got, test.want)
+		}
+		if got := a.StringFixed(test.places); got != test.fixed {
+			t.Errorf("%v.StringFixed(%v) = %v, want %v", test.in, test.places, got, test.fixed)
+		}
+	}
+}
+
+func TestAmountSatoshis(t *testing.T) {
+	if got := MustParseAmount("0.123456789").Satoshis(); got != 12345678 {
+		t.Errorf("Satoshis() = %v, want 12345678", got)
+	}
+	if got := AmountFromSatoshis(150000000).String(); got != "1.5" {
+		t.Errorf("AmountFromSatoshis(150000000) = %v, want 1.5", got)
+	}
+	if got := Satoshis(500).Amount().String(); got != "0.000005" {
+		t.Errorf("Satoshis(500).Amount() = %v, want 0.000005", got)
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	var decoded struct {
+		String Amount
+		Number Amount
+		Empty  Amount
+		Null   Amount
+		Sats   Satoshis
+	}
+	data := `{"String":"0.00125000","Number":12345678901234567890.123456789,"Empty":"","Null":null,"Sats":"100"}`
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := decoded.String.String(); got != "0.00125" {
+		t.Errorf("String = %v, want 0.00125", got)
+	}
+	if got := decoded.Number.String(); got != "12345678901234567890.123456789" {
+		t.Errorf("Number = %v, want 12345678901234567890.123456789", got)
+	}
+	if !decoded.Empty.IsZero() || !decoded.Null.IsZero() {
+		t.Errorf("Empty = %v, Null = %v, want 0", decoded.Empty, decoded.Null)
+	}
+	if decoded.Sats != 100 {
+		t.Errorf("Sats = %v, want 100", decoded.Sats)
+	}
+
+	encoded, err := json.Marshal(MustParseAmount("1.50"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `"1.5"` {
+		t.Errorf("Marshal = %s, want \"1.5\"", encoded)
+	}
+
+	var invalid Amount
+	if err := json.Unmarshal([]byte(`"abc"`), &invalid); err == nil {
+		t.Error("expected an error decoding an invalid amount")
+	}
+}
@@ -0,0 +1,31 @@
+package coinpayments
+
+//API is the set of api commands a Client provides, so that a fake can be used in its place
+type API interface {
+	Balances(request *BalancesRequest) (*BalancesResponse, error)
+	BuyPBNTags(request *BuyPBNTagsRequest) (*BuyPBNTagsResponse, error)
+	ClaimPBNCoupon(request *ClaimPBNCouponRequest) (*ClaimPBNCouponResponse, error)
+	ClaimPBNTag(request *ClaimPBNTagRequest) (*ClaimPBNTagResponse, error)
+	Convert(request *ConvertRequest) (*ConvertResponse, error)
+	ConvertLimits(request *ConvertLimitsRequest) (*ConvertLimitsResponse, error)
+	CreateTransaction(request *CreateTransactionRequest) (*CreateTransactionResponse, error)
+	CreateTransfer(request *CreateTransferRequest) (*CreateTransferResponse, error)
+	CreateWithdrawal(request *CreateWithdrawalRequest) (*CreateWithdrawalResponse, error)
+	DeletePBNTag(request *DeletePBNTagRequest) (*DeletePBNTagResponse, error)
+	GetBasicInfo(request *GetBasicInfoRequest) (*GetBasicInfoResponse, error)
+	GetCallbackAddress(request *GetCallbackAddressRequest) (*GetCallbackAddressResponse, error)
+	GetConversionInfo(request *GetConversionInfoRequest) (*GetConversionInfoResponse, error)
+	GetDepositAddress(request *GetDepositAddressRequest) (*GetDepositAddressResponse, error)
+	GetPBNInfo(request *GetPBNInfoRequest) (*GetPBNInfoResponse, error)
+	GetPBNList(request *GetPBNListRequest) (*GetPBNListResponse, error)
+	GetTxIds(request *GetTxIdsRequest) (*GetTxIdsResponse, error)
+	GetTxInfo(request *GetTxInfoRequest) (*GetTxInfoResponse, error)
+	GetTxInfoMulti(request *GetTxInfoMultiRequest) (*GetTxInfoMultiResponse, error)
+	GetWithdrawalHistory(request *GetWithdrawalHistoryRequest) (*GetWithdrawalHistoryResponse, error)
+	GetWithdrawalInfo(request *GetWithdrawalInfoRequest) (*GetWithdrawalInfoResponse, error)
+	Rates(request *RatesRequest) (*RatesResponse, error)
+	RenewPBNTag(request *RenewPBNTagRequest) (*RenewPBNTagResponse, error)
+	UpdatePBNTag(request *UpdatePBNTagRequest) (*UpdatePBNTagResponse, error)
+}
+
+var _ API = (*Client)(nil)
@@ -1,8 +1,6 @@
 package coinpayments
 
-import "net/url"
-
 type callable interface {
 	command() string
-	values() url.Values
+	Validate() error
 }
@@ -0,0 +1,155 @@
+package coinpayments
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+//Capability is something coinpayments supports for a coin, as listed in its rates capabilities
+type Capability string
+
+const (
+	CapabilityPayments  Capability = "payments"
+	CapabilityWallet    Capability = "wallet"
+	CapabilityTransfers Capability = "transfers"
+	CapabilityConvert   Capability = "convert"
+	CapabilityDestTag   Capability = "dest_tag"
+)
+
+//coinStatusOnline is the rates status of a coin that is working normally
+const coinStatusOnline = "online"
+
+//Coin is the metadata coinpayments reports for a coin or fiat currency
+type Coin struct {
+	Currency     Currency
+	Name         string
+	IsFiat       bool
+	RateBTC      Amount
+	TxFee        Amount
+	Confirms     int
+	Status       string
+	Accepted     bool
+	LastUpdate   time.Time
+	Capabilities []Capability
+}
+
+//Can reports whether the coin has the capability
+func (c *Coin) Can(capability Capability) bool {
+	for _, have := range c.Capabilities {
+		if have == capability {
+			return true
+		}
+	}
+	return false
+}
+
+//IsOnline reports whether the coin is working normally
+func (c *Coin) IsOnline() bool {
+	return strings.EqualFold(c.Status, coinStatusOnline)
+}
+
+//AcceptsPayments reports whether the coin can be used to pay for transactions and is enabled for acceptance
+func (c *Coin) AcceptsPayments() bool {
+	return c.Accepted && c.Can(CapabilityPayments)
+}
+
+//NeedsDestTag reports whether sending the coin needs a destination tag as well as an address
+func (c *Coin) NeedsDestTag() bool {
+	return c.Can(CapabilityDestTag)
+}
+
+//Catalog is the set of coins coinpayments supports, built from a rates call
+type Catalog struct {
+	coins map[string]*Coin
+}
+
+//NewCatalog builds a Catalog from a rates response. Rates fetched with OmitDetails leave out coin names,
+//confirmation counts and capabilities, so Can, WithCapability, CanConvert and AcceptsPayments report false for every
+//coin. Rates fetched without an Acceptance report no coin as accepted, so Accepted returns no coins
+func NewCatalog(rates RatesResponse) *Catalog {
+	catalog := &Catalog{coins: make(map[string]*Coin, len(rates))}
+	for code, rate := range rates {
+		coin := &Coin{
+			Currency:   code,
+			Name:       string(rate.Name),
+			IsFiat:     bool(rate.IsFiat),
+			RateBTC:    rate.RateBTC,
+			TxFee:      rate.TxFee,
+			Confirms:   int(rate.Confirms),
+			Status:     string(rate.Status),
+			Accepted:   bool(rate.Accepted),
+			LastUpdate: rate.LastUpdate.Time,
+		}
+		for _, name := range rate.Capabilities {
+			coin.Capabilities = append(coin.Capabilities, Capability(strings.ToLower(strings.TrimSpace(name))))
+		}
+		catalog.coins[strings.ToUpper(string(code))] = coin
+	}
+	return catalog
+}
+
+//Catalog fetches the rates with coin details and whether each coin is accepted, and builds a Catalog from them
+func (c *Client) Catalog() (*Catalog, error) {
+	rates, err := c.Rates(&RatesRequest{Acceptance: RatesWithAccepted})
+	if err != nil {
+		return nil, fmt.Errorf("coinpayments: error fetching rates for catalog - %v", err)
+	}
+	return NewCatalog(*rates), nil
+}
+
+//Coin returns the coin with the currency code, ignoring case
+func (c *Catalog) Coin(currency Currency) (*Coin, bool) {
+	coin, ok := c.coins[strings.ToUpper(strings.TrimSpace(string(currency)))]
+	return coin, ok
+}
+
+//Coins returns every coin ordered by currency code
+func (c *Catalog) Coins() []*Coin {
+	return c.Filter(func(*Coin) bool { return true })
+}
+
+//Filter returns the coins the function reports true for, ordered by currency code
+func (c *Catalog) Filter(fn func(coin *Coin) bool) []*Coin {
+	var coins []*Coin
+	for _, coin := range c.coins {
+		if fn(coin) {
+			coins = append(coins, coin)
+		}
+	}
+	sort.Slice(coins, func(i, j int) bool {
+		return coins[i].Currency < coins[j].Currency
+	})
+	return coins
+}
+
+//WithCapability returns the coins that have the capability, ordered by currency code
+func (c *Catalog) WithCapability(capability Capability) []*Coin {
+	return c.Filter(func(coin *Coin) bool { return coin.Can(capability) })
+}
+
+//Accepted returns the coins that can be used to pay for transactions, ordered by currency code
+func (c *Catalog) Accepted() []*Coin {
+	return c.Filter((*Coin).AcceptsPayments)
+}
+
+//Fiat returns the fiat currencies, ordered by currency code
+func (c *Catalog) Fiat() []*Coin {
+	return c.Filter(func(coin *Coin) bool { return coin.IsFiat })
+}
+
+//Networks returns every coin with the ticker, such as USDT.ERC20 and USDT.TRC20 for USDT, ordered by currency code
+func (c *Catalog) Networks(ticker string) []*Coin {
+	return c.Filter(func(coin *Coin) bool { return strings.EqualFold(coin.Currency.Ticker(), ticker) })
+}
+
+//CanConvert reports whether coins of one currency can be converted to another
+func (c *Catalog) CanConvert(from, to Currency) bool {
+	fromCoin, ok := c.Coin(from)
+	if !ok || !fromCoin.Can(CapabilityConvert) {
+		return false
+	}
+	toCoin, ok := c.Coin(to)
+	return ok && toCoin.Can(CapabilityConvert) && !from.Equal(to)
+}
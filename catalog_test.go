@@ -0,0 +1,135 @@
+package coinpayments
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const testRatesJSON = `{
+	"BTC": {"is_fiat": 0, "rate_btc": "1.000000000000000000000000", "last_update": "1600000000", "tx_fee": "0.00100000",
+		"status": "online", "name": "Bitcoin", "confirms": "2", "capabilities": ["payments", "wallet", "transfers", "convert"], "accepted": 1},
+	"LTC": {"is_fiat": 0, "rate_btc": "0.00500000", "last_update": "1600000000", "tx_fee": "0.00100000",
+		"status": "online", "name": "Litecoin", "confirms": 3, "capabilities": ["payments", "wallet", "convert"], "accepted": 1},
+	"USDT.ERC20": {"is_fiat": 0, "rate_btc": "0.00010000", "last_update": "1600000000", "tx_fee": "5.00000000",
+		"status": "online", "name": "Tether USD (ERC20)", "confirms": "12", "capabilities": ["payments", "wallet"], "accepted": 0},
+	"USDT.TRC20": {"is_fiat": 0, "rate_btc": "0.00010000", "last_update": "1600000000", "tx_fee": "1.00000000",
+		"status": "maintenance", "name": "Tether USD (TRC20)", "confirms": "20", "capabilities": ["Payments ", "wallet"], "accepted": 1},
+	"XRP": {"is_fiat": 0, "rate_btc": "0.00002000", "last_update": "1600000000", "tx_fee": "0.02000000",
+		"status": "online", "name": "Ripple", "confirms": "1", "capabilities": ["payments", "wallet", "dest_tag"], "accepted": 1},
+	"USD": {"is_fiat": 1, "rate_btc": "0.00010000", "last_update": "1600000000", "tx_fee": "0.00000000",
+		"status": "online", "name": "United States Dollar", "confirms": "1", "capabilities": [], "accepted": 0}
+}`
+
+func testCatalog(t *testing.T, data string) *Catalog {
+	t.Helper()
+
+	var rates RatesResponse
+	if err := json.Unmarshal([]byte(data), &rates); err != nil {
+		t.Fatal(err)
+	}
+	return NewCatalog(rates)
+}
+
+func currencies(coins []*Coin) []Currency {
+	codes := make([]Currency, len(coins))
+	for i, coin := range coins {
+		codes[i] = coin.Currency
+	}
+	return codes
+}
+
+func assertCurrencies(t *testing.T, what string, coins []*Coin, want ...Currency) {
+	t.Helper()
+
+	got := currencies(coins)
+	if len(got) != len(want) {
+		t.Errorf("%v = %v, want %v", what, got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%v = %v, want %v", what, got, want)
+			return
+		}
+	}
+}
+
+func TestCatalogCoin(t *testing.T) {
+	catalog := testCatalog(t, testRatesJSON)
+
+	coin, ok := catalog.Coin("btc")
+	if !ok {
+		t.Fatal("BTC not found")
+	}
+	if coin.Name != "Bitcoin" || coin.Confirms != 2 || !coin.IsOnline() || coin.IsFiat || !coin.Accepted {
+		t.Errorf("unexpected coin %+v", coin)
+	}
+	if got := coin.TxFee.String(); got != "0.001" {
+		t.Errorf("TxFee = %v, want 0.001", got)
+	}
+	if got := coin.LastUpdate.Unix(); got != 1600000000 {
+		t.Errorf("LastUpdate = %v, want 1600000000", got)
+	}
+
+	if _, ok := catalog.Coin("DOGE"); ok {
+		t.Error("DOGE should not be found")
+	}
+	if coin, ok := catalog.Coin(" usdt.trc20 "); !ok || coin.IsOnline() || !coin.Can(CapabilityPayments) {
+		t.Errorf("USDT.TRC20 = %+v, want an offline coin with normalised capabilities", coin)
+	}
+}
+
+func TestCatalogQueries(t *testing.T) {
+	catalog := testCatalog(t, testRatesJSON)
+
+	assertCurrencies(t, "Coins", catalog.Coins(), "BTC", "LTC", "USD", "USDT.ERC20", "USDT.TRC20", "XRP")
+	assertCurrencies(t, "Accepted", catalog.Accepted(), "BTC", "LTC", "USDT.TRC20", "XRP")
+	assertCurrencies(t, "Fiat", catalog.Fiat(), "USD")
+	assertCurrencies(t, "Networks", catalog.Networks("usdt"), "USDT.ERC20", "USDT.TRC20")
+	assertCurrencies(t, "WithCapability", catalog.WithCapability(CapabilityConvert), "BTC", "LTC")
+	assertCurrencies(t, "Filter", catalog.Filter((*Coin).NeedsDestTag), "XRP")
+}
+
+func TestCatalogCanConvert(t *testing.T) {
+	catalog := testCatalog(t, testRatesJSON)
+
+	tests := []struct {
+		from, to Currency
+		want     bool
+	}{
+		{from: "BTC", to: "LTC", want: true},
+		{from: "ltc", to: "btc", want: true},
+		{from: "BTC", to: "BTC"},
+		{from: "BTC", to: "XRP"},
+		{from: "XRP", to: "BTC"},
+		{from: "BTC", to: "DOGE"},
+	}
+
+	for _, test := range tests {
+		if got := catalog.CanConvert(test.from, test.to); got != test.want {
+			t.Errorf("CanConvert(%v, %v) = %v, want %v", test.from, test.to, got, test.want)
+		}
+	}
+}
+
+func TestCatalogWithoutDetails(t *testing.T) {
+	catalog := testCatalog(t, `{
+		"BTC": {"is_fiat": 0, "rate_btc": "1", "last_update": "1600000000", "tx_fee": "0.001", "status": "online"},
+		"LTC": {"is_fiat": 0, "rate_btc": "0.005", "last_update": "1600000000", "tx_fee": "0.001", "status": "online"},
+		"USD": {"is_fiat": 1, "rate_btc": "0.0001", "last_update": "1600000000", "tx_fee": "0", "status": "online"}
+	}`)
+
+	coin, ok := catalog.Coin("BTC")
+	if !ok {
+		t.Fatal("BTC not found")
+	}
+	if coin.Name != "" || coin.Confirms != 0 || coin.Accepted || coin.Can(CapabilityPayments) || coin.AcceptsPayments() {
+		t.Errorf("unexpected details for %+v", coin)
+	}
+	assertCurrencies(t, "Fiat", catalog.Fiat(), "USD")
+	assertCurrencies(t, "Accepted", catalog.Accepted())
+	assertCurrencies(t, "WithCapability", catalog.WithCapability(CapabilityConvert))
+	if catalog.CanConvert("BTC", "LTC") {
+		t.Error("CanConvert should be false without capabilities")
+	}
+}
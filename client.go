@@ -21,6 +21,7 @@ type ClientOption func(client *Client)
 //Client allows programmatic access to the coinpayments api
 type Client struct {
 	client     *http.Client
+	apiURL     string
 	privateKey string
 	publicKey  string
 }
@@ -31,6 +32,7 @@ func NewClient(publicKey, privateKey string, options ...ClientOption) *Client {
 		privateKey: privateKey,
 		publicKey:  publicKey,
 		client:     http.DefaultClient,
+		apiURL:     apiURL,
 	}
 
 	for _, o := range options {
@@ -46,8 +48,22 @@ func WithHTTPClient(httpClient *http.Client) ClientOption {
 	}
 }
 
+//WithAPIURL is an option that makes the Client send api calls to the provided url instead of coinpayments
+func WithAPIURL(url string) ClientOption {
+	return func(client *Client) {
+		client.apiURL = url
+	}
+}
+
 func (c *Client) call(callable callable, response interface{}) error {
-	data := callable.values()
+	if err := callable.Validate(); err != nil {
+		return err
+	}
+
+	data, err := encodeForm(callable)
+	if err != nil {
+		return err
+	}
 
 	data.Add("key", c.publicKey)
 	data.Add("version", apiVersion)
@@ -61,7 +77,7 @@ func (c *Client) call(callable callable, response interface{}) error {
 		return fmt.Errorf("coinpayments: error making HMAC - %v", err)
 	}
 
-	req, err := http.NewRequest("POST", apiURL, strings.NewReader(sData))
+	req, err := http.NewRequest("POST", c.apiURL, strings.NewReader(sData))
 	if err != nil {
 		return fmt.Errorf("coinpayments: error making api request - %v", err)
 	}
@@ -111,9 +127,5 @@ func (c *Client) makeHMAC(data string) (string, error) {
 }
 
 func (c *Client) makeIPNHMAC(data string, ipnSecret string) (string, error) {
-	hash := hmac.New(sha512.New, []byte(ipnSecret))
-	if _, err := hash.Write([]byte(data)); err != nil {
-		return "", err
-	}
-	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+	return SignIPN([]byte(data), ipnSecret), nil
 }
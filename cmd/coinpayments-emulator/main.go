@@ -0,0 +1,74 @@
+//Command coinpayments-emulator serves an emulated coinpayments merchant account for local development.
+//The api is served at /api.php and the emulator is driven through /_emulator/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aidenesco/coinpayments/coinpaymentstest"
+)
+
+type coinAmounts map[string]string
+
+func (c coinAmounts) String() string {
+	var pairs []string
+	for coin, amount := range c {
+		pairs = append(pairs, coin+"="+amount)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (c coinAmounts) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("expected COIN=AMOUNT, got %q", value)
+	}
+	c[parts[0]] = parts[1]
+	return nil
+}
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8080", "address to listen on")
+	publicKey := flag.String("public-key", coinpaymentstest.PublicKey, "api public key to accept")
+	privateKey := flag.String("private-key", coinpaymentstest.PrivateKey, "api private key to accept")
+	ipnSecret := flag.String("ipn-secret", "coinpaymentstest-ipn-secret", "secret to sign IPNs with")
+	merchantID := flag.String("merchant", "coinpaymentstest-merchant", "merchant id of the emulated account")
+	realtime := flag.Bool("realtime", true, "advance the emulated clock with the wall clock")
+	balances := coinAmounts{}
+	flag.Var(balances, "balance", "starting balance as COIN=AMOUNT, may be repeated")
+	flag.Parse()
+
+	options := []coinpaymentstest.EmulatorOption{
+		coinpaymentstest.WithKeys(*publicKey, *privateKey),
+		coinpaymentstest.WithIPNSecret(*ipnSecret),
+		coinpaymentstest.WithMerchantID(*merchantID),
+	}
+	emulator := coinpaymentstest.NewEmulator(options...)
+	for coin, amount := range balances {
+		if err := emulator.SetBalance(coin, amount); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *realtime {
+		go func() {
+			last := time.Now()
+			for now := range time.Tick(time.Second) {
+				emulator.Advance(now.Sub(last))
+				last = now
+			}
+		}()
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api.php", emulator)
+	mux.Handle("/_emulator/", http.StripPrefix("/_emulator", emulator.ControlHandler()))
+
+	log.Printf("coinpayments emulator listening on http://%v/api.php", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
@@ -1,29 +1,28 @@
 package coinpayments
 
-import "net/url"
-
 //Request is the representation of a '' api request
 type BalancesRequest struct {
-	All string
+	//IncludeEmpty includes coins with a zero balance
+	IncludeEmpty bool `form:"all,omitempty"`
+
+	//Deprecated: use IncludeEmpty
+	All string `form:"all,omitempty"`
 }
 
 func (r *BalancesRequest) command() string {
 	return "balances"
 }
 
-func (r *BalancesRequest) values() url.Values {
-	values := url.Values{}
-	if r.All != "" {
-		values.Set("all", r.All)
-	}
-
-	return values
+func (r *BalancesRequest) Validate() error {
+	v := &validator{}
+	v.oneOf("All", r.All, "0", "1")
+	return v.err()
 }
 
-type BalancesResponse map[string]struct {
-	Balance  int    `json:"balance"`
-	Balancef string `json:"balancef"`
-	Status   string `json:"status"`
+type BalancesResponse map[Currency]struct {
+	Balance  Satoshis   `json:"balance"`
+	Balancef Amount     `json:"balancef"`
+	Status   FlexString `json:"status"`
 }
 
 type balancesResult struct {
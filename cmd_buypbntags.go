@@ -1,26 +1,26 @@
 package coinpayments
 
-import "net/url"
-
 type BuyPBNTagsRequest struct {
-	Coin   string
-	Number string
+	Coin Currency `form:"coin,omitempty"`
+	//Count is the number of tags to buy
+	Count int `form:"num,omitempty"`
+
+	//Deprecated: use Count
+	Number string `form:"num,omitempty"`
 }
 
 func (r *BuyPBNTagsRequest) command() string {
 	return "buy_pbn_tags"
 }
 
-func (r *BuyPBNTagsRequest) values() url.Values {
-	values := url.Values{}
-	if r.Coin != "" {
-		values.Set("coin", r.Coin)
+func (r *BuyPBNTagsRequest) Validate() error {
+	v := &validator{}
+	v.currency("Coin", r.Coin, true)
+	if r.Count < 0 {
+		v.add("Count", "cannot be negative")
 	}
-	if r.Number != "" {
-		values.Set("num", r.Number)
-	}
-
-	return values
+	v.integer("Number", r.Number)
+	return v.err()
 }
 
 type BuyPBNTagsResponse []interface{}
@@ -1,26 +1,21 @@
 package coinpayments
 
-import "net/url"
-
 type ClaimPBNCouponRequest struct {
-	Coupon string
+	Coupon string `form:"coupon,omitempty"`
 }
 
 func (r *ClaimPBNCouponRequest) command() string {
-	return "claimm_pbn_coupon"
+	return "claim_pbn_coupon"
 }
 
-func (r *ClaimPBNCouponRequest) values() url.Values {
-	values := url.Values{}
-	if r.Coupon != "" {
-		values.Set("coupon", r.Coupon)
-	}
-
-	return values
+func (r *ClaimPBNCouponRequest) Validate() error {
+	v := &validator{}
+	v.required("Coupon", r.Coupon)
+	return v.err()
 }
 
 type ClaimPBNCouponResponse struct {
-	TagID string `json:"tagid"`
+	TagID FlexString `json:"tagid"`
 }
 
 type claimPBNCouponResult struct {
@@ -1,26 +1,19 @@
 package coinpayments
 
-import "net/url"
-
 type ClaimPBNTagRequest struct {
-	TagID string
-	Name  string
+	TagID string `form:"tagid,omitempty"`
+	Name  string `form:"name,omitempty"`
 }
 
 func (r *ClaimPBNTagRequest) command() string {
 	return "claim_pbn_tag"
 }
 
-func (r *ClaimPBNTagRequest) values() url.Values {
-	values := url.Values{}
-	if r.TagID != "" {
-		values.Set("tagid", r.TagID)
-	}
-	if r.Name != "" {
-		values.Set("name", r.Name)
-	}
-
-	return values
+func (r *ClaimPBNTagRequest) Validate() error {
+	v := &validator{}
+	v.required("TagID", r.TagID)
+	v.required("Name", r.Name)
+	return v.err()
 }
 
 type ClaimPBNTagResponse []interface{}
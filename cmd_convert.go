@@ -1,42 +1,30 @@
 package coinpayments
 
-import "net/url"
-
 type ConvertRequest struct {
-	Amount  string
-	From    string
-	To      string
-	Address string
-	DestTag string
+	Amount  Amount   `form:"amount,omitempty"`
+	From    Currency `form:"from,omitempty"`
+	To      Currency `form:"to,omitempty"`
+	Address string   `form:"address,omitempty"`
+	DestTag string   `form:"dest_tag,omitempty"`
 }
 
 func (r *ConvertRequest) command() string {
 	return "convert"
 }
 
-func (r *ConvertRequest) values() url.Values {
-	values := url.Values{}
-	if r.Amount != "" {
-		values.Set("amount", r.Amount)
-	}
-	if r.From != "" {
-		values.Set("from", r.From)
-	}
-	if r.To != "" {
-		values.Set("to", r.To)
+func (r *ConvertRequest) Validate() error {
+	v := &validator{}
+	v.positive("Amount", r.Amount)
+	v.currency("From", r.From, true)
+	v.currency("To", r.To, true)
+	if r.From != "" && r.From.Equal(r.To) {
+		v.add("To", "must differ from From")
 	}
-	if r.Address != "" {
-		values.Set("address", r.Address)
-	}
-	if r.DestTag != "" {
-		values.Set("dest_tag", r.DestTag)
-	}
-
-	return values
+	return v.err()
 }
 
 type ConvertResponse struct {
-	ID string `json:"id"`
+	ID FlexString `json:"id"`
 }
 
 type convertResult struct {
@@ -1,31 +1,24 @@
 package coinpayments
 
-import "net/url"
-
 type ConvertLimitsRequest struct {
-	From string
-	To   string
+	From Currency `form:"from,omitempty"`
+	To   Currency `form:"to,omitempty"`
 }
 
 func (r *ConvertLimitsRequest) command() string {
 	return "convert_limits"
 }
 
-func (r *ConvertLimitsRequest) values() url.Values {
-	values := url.Values{}
-	if r.From != "" {
-		values.Set("from", r.From)
-	}
-	if r.To != "" {
-		values.Set("to", r.To)
-	}
-
-	return values
+func (r *ConvertLimitsRequest) Validate() error {
+	v := &validator{}
+	v.currency("From", r.From, true)
+	v.currency("To", r.To, true)
+	return v.err()
 }
 
 type ConvertLimitsResponse struct {
-	Min string `json:"min"`
-	Max string `json:"max"`
+	Min Amount `json:"min"`
+	Max Amount `json:"max"`
 }
 
 type convertLimitsResult struct {
@@ -1,84 +1,48 @@
 package coinpayments
 
-import (
-	"net/url"
-)
-
 type CreateTransactionRequest struct {
-	Amount     string
-	Currency1  string
-	Currency2  string
-	BuyerEmail string
-	Address    string
-	BuyerName  string
-	ItemName   string
-	ItemNumber string
-	Invoice    string
-	Custom     string
-	IPNURL     string
-	SuccessURL string
-	CancelURL  string
+	Amount     Amount   `form:"amount,omitempty"`
+	Currency1  Currency `form:"currency1,omitempty"`
+	Currency2  Currency `form:"currency2,omitempty"`
+	BuyerEmail string   `form:"buyer_email,omitempty"`
+	Address    string   `form:"address,omitempty"`
+	BuyerName  string   `form:"buyer_name,omitempty"`
+	ItemName   string   `form:"item_name,omitempty"`
+	ItemNumber string   `form:"item_number,omitempty"`
+	Invoice    string   `form:"invoice,omitempty"`
+	Custom     string   `form:"custom,omitempty"`
+	IPNURL     string   `form:"ipn_url,omitempty"`
+	SuccessURL string   `form:"success_url,omitempty"`
+	CancelURL  string   `form:"cancel_url,omitempty"`
 }
 
 func (r *CreateTransactionRequest) command() string {
 	return "create_transaction"
 }
 
-func (r *CreateTransactionRequest) values() url.Values {
-	values := url.Values{}
-
-	if r.Amount != "" {
-		values.Set("amount", r.Amount)
-	}
-	if r.Currency1 != "" {
-		values.Set("currency1", r.Currency1)
-	}
-	if r.Currency2 != "" {
-		values.Set("currency2", r.Currency2)
-	}
-	if r.BuyerEmail != "" {
-		values.Set("buyer_email", r.BuyerEmail)
-	}
-	if r.Address != "" {
-		values.Set("address", r.Address)
-	}
-	if r.BuyerName != "" {
-		values.Set("buyer_name", r.BuyerName)
-	}
-	if r.ItemName != "" {
-		values.Set("item_name", r.ItemName)
-	}
-	if r.ItemNumber != "" {
-		values.Set("item_number", r.ItemNumber)
-	}
-	if r.Invoice != "" {
-		values.Set("invoice", r.Invoice)
-	}
-	if r.Custom != "" {
-		values.Set("custom", r.Custom)
-	}
-	if r.IPNURL != "" {
-		values.Set("ipn_url", r.IPNURL)
-	}
-	if r.SuccessURL != "" {
-		values.Set("success_url", r.SuccessURL)
-	}
-	if r.CancelURL != "" {
-		values.Set("cancel_url", r.CancelURL)
-	}
-	return values
+func (r *CreateTransactionRequest) Validate() error {
+	v := &validator{}
+	v.positive("Amount", r.Amount)
+	v.currency("Currency1", r.Currency1, true)
+	v.currency("Currency2", r.Currency2, true)
+	v.required("BuyerEmail", r.BuyerEmail)
+	v.email("BuyerEmail", r.BuyerEmail)
+	v.url("IPNURL", r.IPNURL)
+	v.url("SuccessURL", r.SuccessURL)
+	v.url("CancelURL", r.CancelURL)
+	return v.err()
 }
 
 type CreateTransactionResponse struct {
-	Amount         string `json:"amount"`
-	Address        string `json:"address"`
-	DestTag        string `json:"dest_tag"`
-	TxnId          string `json:"txn_id"`
-	ConfirmsNeeded string `json:"confirms_needed"`
-	Timeout        int    `json:"timeout"`
-	CheckoutURL    string `json:"checkout_url"`
-	StatusURL      string `json:"status_url"`
-	QRCodeURL      string `json:"qrcode_url"`
+	Amount         Amount          `json:"amount"`
+	Address        FlexString      `json:"address"`
+	DestTag        FlexString      `json:"dest_tag"`
+	TxnId          FlexString      `json:"txn_id"`
+	ConfirmsNeeded FlexInt         `json:"confirms_needed"`
+	Timeout        DurationSeconds `json:"timeout"`
+	CheckoutURL    FlexString      `json:"checkout_url"`
+	StatusURL      FlexString      `json:"status_url"`
+	QRCodeURL      FlexString      `json:"qrcode_url"`
 }
 
 type createTransactionResult struct {
@@ -1,47 +1,34 @@
 package coinpayments
 
-import "net/url"
-
 type CreateTransferRequest struct {
-	Amount      string
-	Currency    string
-	Merchant    string
-	PBNTag      string
-	AutoConfirm string
-	Note        string
+	Amount   Amount   `form:"amount,omitempty"`
+	Currency Currency `form:"currency,omitempty"`
+	Merchant string   `form:"merchant,omitempty"`
+	PBNTag   string   `form:"pbntag,omitempty"`
+	Note     string   `form:"note,omitempty"`
+	//SkipConfirmation sends the transfer without waiting for email confirmation
+	SkipConfirmation bool `form:"auto_confirm,omitempty"`
+
+	//Deprecated: use SkipConfirmation
+	AutoConfirm string `form:"auto_confirm,omitempty"`
 }
 
 func (r *CreateTransferRequest) command() string {
 	return "create_transfer"
 }
 
-func (r *CreateTransferRequest) values() url.Values {
-	values := url.Values{}
-	if r.Amount != "" {
-		values.Set("amount", r.Amount)
-	}
-	if r.Currency != "" {
-		values.Set("currency", r.Currency)
-	}
-	if r.Merchant != "" {
-		values.Set("merchant", r.Merchant)
-	}
-	if r.PBNTag != "" {
-		values.Set("pbntag", r.PBNTag)
-	}
-	if r.AutoConfirm != "" {
-		values.Set("auto_confirm", r.AutoConfirm)
-	}
-	if r.Note != "" {
-		values.Set("note", r.Note)
-	}
-
-	return values
+func (r *CreateTransferRequest) Validate() error {
+	v := &validator{}
+	v.positive("Amount", r.Amount)
+	v.currency("Currency", r.Currency, true)
+	v.exactlyOne("Merchant", r.Merchant, "PBNTag", r.PBNTag)
+	v.oneOf("AutoConfirm", r.AutoConfirm, "0", "1")
+	return v.err()
 }
 
 type CreateTransferResponse struct {
-	ID     string `json:"id"`
-	Status int    `json:"status"`
+	ID     FlexString     `json:"id"`
+	Status TransferStatus `json:"status"`
 }
 
 type createTransferResult struct {
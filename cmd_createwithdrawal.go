@@ -1,64 +1,48 @@
 package coinpayments
 
-import "net/url"
-
 type CreateWithdrawalRequest struct {
-	Amount      string
-	AddTxFee    string
-	Currency    string
-	Currency2   string
-	Address     string
-	PBNTag      string
-	DestTag     string
-	IPNURL      string
-	AutoConfirm string
-	Note        string
+	Amount    Amount   `form:"amount,omitempty"`
+	Currency  Currency `form:"currency,omitempty"`
+	Currency2 Currency `form:"currency2,omitempty"`
+	Address   string   `form:"address,omitempty"`
+	PBNTag    string   `form:"pbntag,omitempty"`
+	DestTag   string   `form:"dest_tag,omitempty"`
+	IPNURL    string   `form:"ipn_url,omitempty"`
+	Note      string   `form:"note,omitempty"`
+	//SenderPaysFee adds the coin's transaction fee to the amount, so it is not taken from what the receiver gets
+	SenderPaysFee bool `form:"add_tx_fee,omitempty"`
+	//SkipConfirmation sends the withdrawal without waiting for email confirmation
+	SkipConfirmation bool `form:"auto_confirm,omitempty"`
+
+	//Deprecated: use SenderPaysFee
+	AddTxFee string `form:"add_tx_fee,omitempty"`
+	//Deprecated: use SkipConfirmation
+	AutoConfirm string `form:"auto_confirm,omitempty"`
 }
 
 func (r *CreateWithdrawalRequest) command() string {
 	return "create_withdrawal"
 }
 
-func (r *CreateWithdrawalRequest) values() url.Values {
-	values := url.Values{}
-	if r.Amount != "" {
-		values.Set("amount", r.Amount)
-	}
-	if r.AddTxFee != "" {
-		values.Set("add_tx_fee", r.AddTxFee)
-	}
-	if r.Currency != "" {
-		values.Set("currency", r.Currency)
-	}
-	if r.Currency2 != "" {
-		values.Set("currency2", r.Currency2)
-	}
-	if r.Address != "" {
-		values.Set("address", r.Address)
-	}
-	if r.PBNTag != "" {
-		values.Set("pbntag", r.PBNTag)
-	}
-	if r.DestTag != "" {
-		values.Set("dest_tag", r.DestTag)
-	}
-	if r.IPNURL != "" {
-		values.Set("ipn_url", r.IPNURL)
-	}
-	if r.AutoConfirm != "" {
-		values.Set("auto_confirm", r.AutoConfirm)
-	}
-	if r.Note != "" {
-		values.Set("note", r.Note)
-	}
-
-	return values
+func (r *CreateWithdrawalRequest) Validate() error {
+	v := &validator{}
+	v.currency("Currency2", r.Currency2, false)
+	v.positive("Amount", r.Amount)
+	v.currency("Currency", r.Currency, true)
+	v.exactlyOne("Address", r.Address, "PBNTag", r.PBNTag)
+	if r.DestTag != "" && r.Address == "" {
+		v.add("DestTag", "requires Address")
+	}
+	v.url("IPNURL", r.IPNURL)
+	v.oneOf("AddTxFee", r.AddTxFee, "0", "1")
+	v.oneOf("AutoConfirm", r.AutoConfirm, "0", "1")
+	return v.err()
 }
 
 type CreateWithdrawalResponse struct {
-	ID     string `json:"id"`
-	Status int    `json:"status"`
-	Amount string `json:"amount"`
+	ID     FlexString       `json:"id"`
+	Status WithdrawalStatus `json:"status"`
+	Amount Amount           `json:"amount"`
 }
 
 type createWithdrawalResult struct {
@@ -1,22 +1,17 @@
 package coinpayments
 
-import "net/url"
-
 type DeletePBNTagRequest struct {
-	TagID string
+	TagID string `form:"tagid,omitempty"`
 }
 
 func (r *DeletePBNTagRequest) command() string {
 	return "delete_pbn_tag"
 }
 
-func (r *DeletePBNTagRequest) values() url.Values {
-	values := url.Values{}
-	if r.TagID != "" {
-		values.Set("tagid", r.TagID)
-	}
-
-	return values
+func (r *DeletePBNTagRequest) Validate() error {
+	v := &validator{}
+	v.required("TagID", r.TagID)
+	return v.err()
 }
 
 type DeletePBNTagResponse []interface{}
@@ -1,24 +1,20 @@
 package coinpayments
 
-import "net/url"
-
 type GetBasicInfoRequest struct{}
 
 func (r *GetBasicInfoRequest) command() string {
 	return "get_basic_info"
 }
 
-func (r *GetBasicInfoRequest) values() url.Values {
-	values := url.Values{}
-
-	return values
+func (r *GetBasicInfoRequest) Validate() error {
+	return nil
 }
 
 type GetBasicInfoResponse struct {
-	Username   string `json:"username"`
-	MerchantID string `json:"merchant_id"`
-	Email      string `json:"email"`
-	PublicName string `json:"public_name"`
+	Username   FlexString `json:"username"`
+	MerchantID FlexString `json:"merchant_id"`
+	Email      FlexString `json:"email"`
+	PublicName FlexString `json:"public_name"`
 }
 
 type getBasicInfoResult struct {
@@ -1,36 +1,26 @@
 package coinpayments
 
-import "net/url"
-
 type GetCallbackAddressRequest struct {
-	Currency string
-	IPNURL   string
-	Label    string
+	Currency Currency `form:"currency,omitempty"`
+	IPNURL   string   `form:"ipn_url,omitempty"`
+	Label    string   `form:"label,omitempty"`
 }
 
 func (r *GetCallbackAddressRequest) command() string {
 	return "get_callback_address"
 }
 
-func (r *GetCallbackAddressRequest) values() url.Values {
-	values := url.Values{}
-
-	if r.Currency != "" {
-		values.Set("currency", r.Currency)
-	}
-	if r.IPNURL != "" {
-		values.Set("ipn_url", r.IPNURL)
-	}
-	if r.Label != "" {
-		values.Set("label", r.Label)
-	}
-	return values
+func (r *GetCallbackAddressRequest) Validate() error {
+	v := &validator{}
+	v.currency("Currency", r.Currency, true)
+	v.url("IPNURL", r.IPNURL)
+	return v.err()
 }
 
 type GetCallbackAddressResponse struct {
-	Address string `json:"address"`
-	PubKey  string `json:"pubkey"`
-	DestTag string `json:"dest_tag"`
+	Address FlexString `json:"address"`
+	PubKey  FlexString `json:"pubkey"`
+	DestTag FlexString `json:"dest_tag"`
 }
 
 type getCallbackAddressResult struct {
@@ -1,34 +1,29 @@
 package coinpayments
 
-import "net/url"
-
 type GetConversionInfoRequest struct {
-	ID string
+	ID string `form:"id,omitempty"`
 }
 
 func (r *GetConversionInfoRequest) command() string {
 	return "get_conversion_info"
 }
 
-func (r *GetConversionInfoRequest) values() url.Values {
-	values := url.Values{}
-	if r.ID != "" {
-		values.Set("id", r.ID)
-	}
-
-	return values
+func (r *GetConversionInfoRequest) Validate() error {
+	v := &validator{}
+	v.required("ID", r.ID)
+	return v.err()
 }
 
 type GetConversionInfoResponse struct {
-	TimeCreated string `json:"time_created"`
-	Status      int    `json:"status"`
-	StatusText  string `json:"status_text"`
-	Coin1       string `json:"coin1"`
-	Coin2       string `json:"coin2"`
-	AmountSent  int    `json:"amount_sent"`
-	AmountSentf string `json:"amount_sentf"`
-	Received    int    `json:"received"`
-	Receivedf   string `json:"receivedf"`
+	TimeCreated UnixTime         `json:"time_created"`
+	Status      ConversionStatus `json:"status"`
+	StatusText  FlexString       `json:"status_text"`
+	Coin1       Currency         `json:"coin1"`
+	Coin2       Currency         `json:"coin2"`
+	AmountSent  Satoshis         `json:"amount_sent"`
+	AmountSentf Amount           `json:"amount_sentf"`
+	Received    Satoshis         `json:"received"`
+	Receivedf   Amount           `json:"receivedf"`
 }
 
 type getConversionInfoResult struct {
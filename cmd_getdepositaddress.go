@@ -1,28 +1,23 @@
 package coinpayments
 
-import "net/url"
-
 type GetDepositAddressRequest struct {
-	Currency string
+	Currency Currency `form:"currency,omitempty"`
 }
 
 func (r *GetDepositAddressRequest) command() string {
 	return "get_deposit_address"
 }
 
-func (r *GetDepositAddressRequest) values() url.Values {
-	values := url.Values{}
-	if r.Currency != "" {
-		values.Set("currency", r.Currency)
-	}
-
-	return values
+func (r *GetDepositAddressRequest) Validate() error {
+	v := &validator{}
+	v.currency("Currency", r.Currency, true)
+	return v.err()
 }
 
 type GetDepositAddressResponse struct {
-	Address string `json:"address"`
-	PubKey  string `json:"pubkey"`
-	DestTag int    `json:"dest_tag"`
+	Address FlexString `json:"address"`
+	PubKey  FlexString `json:"pubkey"`
+	DestTag FlexString `json:"dest_tag"`
 }
 
 type getDepositAddressResult struct {
@@ -1,38 +1,33 @@
 package coinpayments
 
-import "net/url"
-
 type GetPBNInfoRequest struct {
-	PBNTag string
+	PBNTag string `form:"pbntag,omitempty"`
 }
 
 func (r *GetPBNInfoRequest) command() string {
 	return "get_pbn_info"
 }
 
-func (r *GetPBNInfoRequest) values() url.Values {
-	values := url.Values{}
-	if r.PBNTag != "" {
-		values.Set("pbntag", r.PBNTag)
-	}
-
-	return values
+func (r *GetPBNInfoRequest) Validate() error {
+	v := &validator{}
+	v.required("PBNTag", r.PBNTag)
+	return v.err()
 }
 
 type GetPBNInfoResponse struct {
-	PBNTag       string `json:"pbntag"`
-	Merchant     string `json:"merchant"`
-	ProfileName  string `json:"profile_name"`
-	ProfileURL   string `json:"profile_url"`
-	ProfileEmail string `json:"profile_email"`
-	ProfileImage string `json:"profile_image"`
-	MemberSince  int    `json:"member_since"`
+	PBNTag       FlexString `json:"pbntag"`
+	Merchant     FlexString `json:"merchant"`
+	ProfileName  FlexString `json:"profile_name"`
+	ProfileURL   FlexString `json:"profile_url"`
+	ProfileEmail FlexString `json:"profile_email"`
+	ProfileImage FlexString `json:"profile_image"`
+	MemberSince  UnixTime   `json:"member_since"`
 	Feedback     struct {
-		Positive int    `json:"pos"`
-		Negative int    `json:"neg"`
-		Neutral  string `json:"neut"`
-		Total    int    `json:"total"`
-		Percent  string `json:"percent"`
+		Positive FlexInt    `json:"pos"`
+		Negative FlexInt    `json:"neg"`
+		Neutral  FlexInt    `json:"neut"`
+		Total    FlexInt    `json:"total"`
+		Percent  FlexString `json:"percent"`
 	} `json:"feedback"`
 }
 
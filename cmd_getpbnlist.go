@@ -1,23 +1,19 @@
 package coinpayments
 
-import "net/url"
-
 type GetPBNListRequest struct{}
 
 func (r *GetPBNListRequest) command() string {
 	return "get_pbn_list"
 }
 
-func (r *GetPBNListRequest) values() url.Values {
-	values := url.Values{}
-
-	return values
+func (r *GetPBNListRequest) Validate() error {
+	return nil
 }
 
 type GetPBNListResponse []struct {
-	TagID       string `json:"tagid"`
-	PBGTag      string `json:"pbgtag"`
-	TimeExpires int    `json:"time_expires"`
+	TagID       FlexString `json:"tagid"`
+	PBGTag      FlexString `json:"pbgtag"`
+	TimeExpires UnixTime   `json:"time_expires"`
 }
 
 type getPBNListResult struct {
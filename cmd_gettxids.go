@@ -1,35 +1,39 @@
 package coinpayments
 
-import "net/url"
+import "time"
 
 type GetTxIdsRequest struct {
-	Limit string
-	Start string
-	Newer string
+	//MaxResults is the most results to return, the api default is 25 and the maximum 100
+	MaxResults int `form:"limit,omitempty"`
+	//Offset is the number of results to skip
+	Offset int `form:"start,omitempty"`
+	//Since returns only transactions created at or after this time
+	Since time.Time `form:"newer,omitempty"`
+
+	//Deprecated: use MaxResults
+	Limit string `form:"limit,omitempty"`
+	//Deprecated: use Offset
+	Start string `form:"start,omitempty"`
+	//Deprecated: use Since
+	Newer string `form:"newer,omitempty"`
 	//Causes issues parsing response
-	//All   string
+	//All string `form:"all,omitempty"`
 }
 
 func (r *GetTxIdsRequest) command() string {
 	return "get_tx_ids"
 }
 
-func (r *GetTxIdsRequest) values() url.Values {
-	values := url.Values{}
-	if r.Limit != "" {
-		values.Set("limit", r.Limit)
+func (r *GetTxIdsRequest) Validate() error {
+	v := &validator{}
+	v.between("MaxResults", r.MaxResults, 0, 100)
+	if r.Offset < 0 {
+		v.add("Offset", "cannot be negative")
 	}
-	if r.Start != "" {
-		values.Set("start", r.Start)
-	}
-	if r.Newer != "" {
-		values.Set("newer", r.Newer)
-	}
-	//if r.All != "" {
-	//	values.Set("all", r.All)
-	//}
-
-	return values
+	v.integer("Limit", r.Limit)
+	v.integer("Start", r.Start)
+	v.integer("Newer", r.Newer)
+	return v.err()
 }
 
 type GetTxIdsResponse []string
@@ -1,52 +1,54 @@
 package coinpayments
 
-import "net/url"
+import "strings"
 
 type GetTxInfoRequest struct {
-	TXID string
-	Full string
+	TXID string `form:"txid,omitempty"`
+	//IncludeCheckout includes the raw checkout and shipping data of the payment
+	IncludeCheckout bool `form:"full,omitempty"`
+
+	//Deprecated: use IncludeCheckout
+	Full string `form:"full,omitempty"`
 }
 
 func (r *GetTxInfoRequest) command() string {
 	return "get_tx_info"
 }
 
-func (r *GetTxInfoRequest) values() url.Values {
-	values := url.Values{}
-
-	if r.TXID != "" {
-		values.Set("txid", r.TXID)
-	}
-	if r.Full != "" {
-		values.Set("full", r.Full)
+func (r *GetTxInfoRequest) Validate() error {
+	v := &validator{}
+	v.required("TXID", r.TXID)
+	if strings.Contains(r.TXID, "|") {
+		v.add("TXID", "holds several ids, use GetTxInfoMulti")
 	}
-	return values
+	v.oneOf("Full", r.Full, "0", "1")
+	return v.err()
 }
 
 type GetTxInfoResponse struct {
-	TimeCreated      int    `json:"time_created"`
-	TimeExpires      int    `json:"time_expires"`
-	Status           int    `json:"status"`
-	StatusText       string `json:"status_text"`
-	Type             string `json:"type"`
-	Coin             string `json:"coin"`
-	Amount           int    `json:"amount"`
-	Amountf          string `json:"amountf"`
-	Received         int    `json:"received"`
-	Receivedf        string `json:"receivedf"`
-	ReceivedConfirms int    `json:"recv_confirms"`
-	PaymentAddress   string `json:"payment_address"`
+	TimeCreated      UnixTime      `json:"time_created"`
+	TimeExpires      UnixTime      `json:"time_expires"`
+	Status           PaymentStatus `json:"status"`
+	StatusText       FlexString    `json:"status_text"`
+	Type             FlexString    `json:"type"`
+	Coin             Currency      `json:"coin"`
+	Amount           Satoshis      `json:"amount"`
+	Amountf          Amount        `json:"amountf"`
+	Received         Satoshis      `json:"received"`
+	Receivedf        Amount        `json:"receivedf"`
+	ReceivedConfirms FlexInt       `json:"recv_confirms"`
+	PaymentAddress   FlexString    `json:"payment_address"`
 	Checkout         struct {
-		Currency   string        `json:"currency"`
-		Amount     int           `json:"amount"`
-		Test       int           `json:"test"`
-		ItemNumber string        `json:"item_number"`
-		ItemName   string        `json:"item_name"`
+		Currency   Currency      `json:"currency"`
+		Amount     Satoshis      `json:"amount"`
+		Test       FlexBool      `json:"test"`
+		ItemNumber FlexString    `json:"item_number"`
+		ItemName   FlexString    `json:"item_name"`
 		Details    []interface{} `json:"details"`
-		Invoice    string        `json:"invoice"`
-		Custom     string        `json:"custom"`
-		IPNURL     string        `json:"ipn_url"`
-		Amountf    int           `json:"amountf"`
+		Invoice    FlexString    `json:"invoice"`
+		Custom     FlexString    `json:"custom"`
+		IPNURL     FlexString    `json:"ipn_url"`
+		Amountf    Amount        `json:"amountf"`
 	} `json:"checkout,omitempty"`
 	Shipping []interface{} `json:"shipping,omitempty"`
 }
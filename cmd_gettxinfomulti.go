@@ -1,37 +1,41 @@
 package coinpayments
 
-import "net/url"
+import "strings"
+
+//maxTxInfoMulti is the most transaction ids get_tx_info_multi accepts in one call
+const maxTxInfoMulti = 25
 
 type GetTxInfoMultiRequest struct {
-	TXID string
+	TXID string `form:"txid,omitempty"`
 }
 
 func (r *GetTxInfoMultiRequest) command() string {
 	return "get_tx_info_multi"
 }
 
-func (r *GetTxInfoMultiRequest) values() url.Values {
-	values := url.Values{}
-	if r.TXID != "" {
-		values.Set("txid", r.TXID)
+func (r *GetTxInfoMultiRequest) Validate() error {
+	v := &validator{}
+	v.required("TXID", r.TXID)
+	if n := len(strings.Split(r.TXID, "|")); n > maxTxInfoMulti {
+		v.add("TXID", "holds %d ids, at most %d are allowed", n, maxTxInfoMulti)
 	}
-	return values
+	return v.err()
 }
 
 type GetTxInfoMultiResponse map[string]struct {
-	Error            string `json:"error"`
-	TimeCreated      int    `json:"time_created"`
-	TimeExpires      int    `json:"time_expires"`
-	Status           int    `json:"status"`
-	StatusText       string `json:"status_text"`
-	Type             string `json:"type"`
-	Coin             string `json:"coin"`
-	Amount           int    `json:"amount"`
-	Amountf          string `json:"amountf"`
-	Received         int    `json:"received"`
-	Recievedf        string `json:"recievedf"`
-	RecievedConfirms int    `json:"recv_confirms"`
-	PaymentAddress   string `json:"payment_address"`
+	Error            FlexString    `json:"error"`
+	TimeCreated      UnixTime      `json:"time_created"`
+	TimeExpires      UnixTime      `json:"time_expires"`
+	Status           PaymentStatus `json:"status"`
+	StatusText       FlexString    `json:"status_text"`
+	Type             FlexString    `json:"type"`
+	Coin             Currency      `json:"coin"`
+	Amount           Satoshis      `json:"amount"`
+	Amountf          Amount        `json:"amountf"`
+	Received         Satoshis      `json:"received"`
+	Recievedf        Amount        `json:"recievedf"`
+	RecievedConfirms FlexInt       `json:"recv_confirms"`
+	PaymentAddress   FlexString    `json:"payment_address"`
 }
 
 type getTxInfoMultiResult struct {
@@ -1,44 +1,51 @@
 package coinpayments
 
-import "net/url"
+import "time"
 
 type GetWithdrawalHistoryRequest struct {
-	Limit string
-	Start string
-	Newer string
+	//MaxResults is the most results to return, the api default is 25 and the maximum 100
+	MaxResults int `form:"limit,omitempty"`
+	//Offset is the number of results to skip
+	Offset int `form:"start,omitempty"`
+	//Since returns only withdrawals created at or after this time
+	Since time.Time `form:"newer,omitempty"`
+
+	//Deprecated: use MaxResults
+	Limit string `form:"limit,omitempty"`
+	//Deprecated: use Offset
+	Start string `form:"start,omitempty"`
+	//Deprecated: use Since
+	Newer string `form:"newer,omitempty"`
 }
 
 func (r *GetWithdrawalHistoryRequest) command() string {
 	return "get_withdrawal_history"
 }
 
-func (r *GetWithdrawalHistoryRequest) values() url.Values {
-	values := url.Values{}
-	if r.Limit != "" {
-		values.Set("limit", r.Limit)
+func (r *GetWithdrawalHistoryRequest) Validate() error {
+	v := &validator{}
+	v.between("MaxResults", r.MaxResults, 0, 100)
+	if r.Offset < 0 {
+		v.add("Offset", "cannot be negative")
 	}
-	if r.Start != "" {
-		values.Set("start", r.Start)
-	}
-	if r.Newer != "" {
-		values.Set("newer", r.Newer)
-	}
-
-	return values
+	v.integer("Limit", r.Limit)
+	v.integer("Start", r.Start)
+	v.integer("Newer", r.Newer)
+	return v.err()
 }
 
 type GetWithdrawalHistoryResponse []struct {
-	ID          string `json:"id"`
-	TimeCreated int    `json:"time_created"`
-	Status      int    `json:"status"`
-	StatusText  string `json:"status_text"`
-	Coin        string `json:"coin"`
-	Amount      int    `json:"amount"`
-	Amountf     string `json:"amountf"`
-	Note        string `json:"note"`
-	SendAddress string `json:"send_address"`
-	SendDestTag string `json:"send_dest_tag"`
-	SendTXID    string `json:"send_txid"`
+	ID          FlexString       `json:"id"`
+	TimeCreated UnixTime         `json:"time_created"`
+	Status      WithdrawalStatus `json:"status"`
+	StatusText  FlexString       `json:"status_text"`
+	Coin        Currency         `json:"coin"`
+	Amount      Satoshis         `json:"amount"`
+	Amountf     Amount           `json:"amountf"`
+	Note        FlexString       `json:"note"`
+	SendAddress FlexString       `json:"send_address"`
+	SendDestTag FlexString       `json:"send_dest_tag"`
+	SendTXID    FlexString       `json:"send_txid"`
 }
 
 type getWithdrawalHistoryResult struct {
@@ -1,34 +1,29 @@
 package coinpayments
 
-import "net/url"
-
 type GetWithdrawalInfoRequest struct {
-	ID string
+	ID string `form:"id,omitempty"`
 }
 
 func (r *GetWithdrawalInfoRequest) command() string {
 	return "get_withdrawal_info"
 }
 
-func (r *GetWithdrawalInfoRequest) values() url.Values {
-	values := url.Values{}
-	if r.ID != "" {
-		values.Set("id", r.ID)
-	}
-
-	return values
+func (r *GetWithdrawalInfoRequest) Validate() error {
+	v := &validator{}
+	v.required("ID", r.ID)
+	return v.err()
 }
 
 type GetWithdrawalInfoResponse struct {
-	TimeCreated int    `json:"time_created"`
-	Status      int    `json:"status"`
-	StatusText  string `json:"status_text"`
-	Coin        string `json:"coin"`
-	Amount      int    `json:"amount"`
-	Amountf     string `json:"amountf"`
-	Note        string `json:"note"`
-	SendAddress string `json:"send_address"`
-	SendTXID    string `json:"send_txid"`
+	TimeCreated UnixTime         `json:"time_created"`
+	Status      WithdrawalStatus `json:"status"`
+	StatusText  FlexString       `json:"status_text"`
+	Coin        Currency         `json:"coin"`
+	Amount      Satoshis         `json:"amount"`
+	Amountf     Amount           `json:"amountf"`
+	Note        FlexString       `json:"note"`
+	SendAddress FlexString       `json:"send_address"`
+	SendTXID    FlexString       `json:"send_txid"`
 }
 
 type getWithdrawalInfoResult struct {
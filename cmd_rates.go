@@ -1,40 +1,51 @@
 package coinpayments
 
-import (
-	"net/url"
+//RatesAcceptance controls how a rates call reports the coins enabled for acceptance
+type RatesAcceptance int
+
+const (
+	//RatesWithAccepted reports whether each coin is enabled for acceptance
+	RatesWithAccepted RatesAcceptance = 1
+	//RatesOnlyAccepted reports whether each coin is enabled for acceptance and leaves out cryptocurrencies that are
+	//not. Fiat currencies are always included
+	RatesOnlyAccepted RatesAcceptance = 2
 )
 
 type RatesRequest struct {
-	Short    string
-	Accepted string
+	//OmitDetails leaves out coin names and confirmation counts
+	OmitDetails bool            `form:"short,omitempty"`
+	Acceptance  RatesAcceptance `form:"accepted,omitempty"`
+
+	//Deprecated: use OmitDetails
+	Short string `form:"short,omitempty"`
+	//Deprecated: use Acceptance
+	Accepted string `form:"accepted,omitempty"`
 }
 
 func (r *RatesRequest) command() string {
 	return "rates"
 }
 
-func (r *RatesRequest) values() url.Values {
-	values := url.Values{}
-
-	if r.Short != "" {
-		values.Set("short", r.Short)
-	}
-	if r.Accepted != "" {
-		values.Set("accepted", r.Accepted)
+func (r *RatesRequest) Validate() error {
+	v := &validator{}
+	if r.Acceptance != 0 && r.Acceptance != RatesWithAccepted && r.Acceptance != RatesOnlyAccepted {
+		v.add("Acceptance", "%d is not a RatesAcceptance", r.Acceptance)
 	}
-	return values
+	v.oneOf("Short", r.Short, "0", "1")
+	v.oneOf("Accepted", r.Accepted, "0", "1", "2")
+	return v.err()
 }
 
-type RatesResponse map[string]struct {
-	IsFiat       int      `json:"is_fiat"`
-	RateBTC      string   `json:"rate_btc"`
-	LastUpdate   string   `json:"last_update"`
-	TxFee        string   `json:"tx_fee"`
-	Status       string   `json:"status"`
-	Name         string   `json:"name"`
-	Confirms     string   `json:"confirms"`
-	Capabilities []string `json:"capabilities"`
-	Accepted     int      `json:"accepted"`
+type RatesResponse map[Currency]struct {
+	IsFiat       FlexBool   `json:"is_fiat"`
+	RateBTC      Amount     `json:"rate_btc"`
+	LastUpdate   UnixTime   `json:"last_update"`
+	TxFee        Amount     `json:"tx_fee"`
+	Status       FlexString `json:"status"`
+	Name         FlexString `json:"name"`
+	Confirms     FlexInt    `json:"confirms"`
+	Capabilities []string   `json:"capabilities"`
+	Accepted     FlexBool   `json:"accepted"`
 }
 
 type ratesResult struct {
@@ -1,30 +1,28 @@
 package coinpayments
 
-import "net/url"
-
 type RenewPBNTagRequest struct {
-	TagID string
-	Coin  string
-	Years string
+	TagID string   `form:"tagid,omitempty"`
+	Coin  Currency `form:"coin,omitempty"`
+	//NumYears is the number of years to renew the tag for
+	NumYears int `form:"years,omitempty"`
+
+	//Deprecated: use NumYears
+	Years string `form:"years,omitempty"`
 }
 
 func (r *RenewPBNTagRequest) command() string {
 	return "renew_pbn_tag"
 }
 
-func (r *RenewPBNTagRequest) values() url.Values {
-	values := url.Values{}
-	if r.TagID != "" {
-		values.Set("tagid", r.TagID)
-	}
-	if r.Coin != "" {
-		values.Set("coin", r.Coin)
+func (r *RenewPBNTagRequest) Validate() error {
+	v := &validator{}
+	v.required("TagID", r.TagID)
+	v.currency("Coin", r.Coin, true)
+	if r.NumYears < 0 {
+		v.add("NumYears", "cannot be negative")
 	}
-	if r.Years != "" {
-		values.Set("years", r.Years)
-	}
-
-	return values
+	v.integer("Years", r.Years)
+	return v.err()
 }
 
 type RenewPBNTagResponse []interface{}
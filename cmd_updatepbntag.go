@@ -1,40 +1,24 @@
 package coinpayments
 
-import (
-	"net/url"
-)
-
 type UpdatePBNTagRequest struct {
-	TagID string
-	Name  string
-	Email string
-	URL   string
-	Image string
+	TagID string `form:"tagid,omitempty"`
+	Name  string `form:"name,omitempty"`
+	Email string `form:"email,omitempty"`
+	URL   string `form:"url,omitempty"`
+	Image string `form:"image,omitempty"`
 }
 
 func (r *UpdatePBNTagRequest) command() string {
 	return "update_pbn_tag"
 }
 
-func (r *UpdatePBNTagRequest) values() url.Values {
-	values := url.Values{}
-	if r.TagID != "" {
-		values.Set("tagid", r.TagID)
-	}
-	if r.Name != "" {
-		values.Set("name", r.Name)
-	}
-	if r.Email != "" {
-		values.Set("email", r.Email)
-	}
-	if r.URL != "" {
-		values.Set("url", r.URL)
-	}
-	if r.Image != "" {
-		values.Set("image", r.Image)
-	}
-
-	return values
+func (r *UpdatePBNTagRequest) Validate() error {
+	v := &validator{}
+	v.required("TagID", r.TagID)
+	v.email("Email", r.Email)
+	v.url("URL", r.URL)
+	v.url("Image", r.Image)
+	return v.err()
 }
 
 type UpdatePBNTagResponse []interface{}
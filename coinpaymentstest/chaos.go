@@ -0,0 +1,258 @@
+package coinpaymentstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+//Fault is a failure a ChaosTransport can inject into an api call
+type Fault int
+
+const (
+	//FaultNone lets the api call through untouched
+	FaultNone Fault = iota
+	//FaultTimeout fails the api call with a timeout error
+	FaultTimeout
+	//FaultServerError answers the api call with a 503 response
+	FaultServerError
+	//FaultTruncated lets the api call through but cuts the response body in half
+	FaultTruncated
+	//FaultSlow lets the api call through after the configured delay
+	FaultSlow
+	//FaultAPIError answers the api call with an 'error' envelope
+	FaultAPIError
+)
+
+func (f Fault) String() string {
+	switch f {
+	case FaultNone:
+		return "none"
+	case FaultTimeout:
+		return "timeout"
+	case FaultServerError:
+		return "server_error"
+	case FaultTruncated:
+		return "truncated"
+	case FaultSlow:
+		return "slow"
+	case FaultAPIError:
+		return "api_error"
+	}
+	return fmt.Sprintf("Fault(%d)", int(f))
+}
+
+//InjectedFault records the fault a ChaosTransport chose for an api call
+type InjectedFault struct {
+	Command string
+	Fault   Fault
+}
+
+type chaosRule struct {
+	probabilities map[Fault]float64
+	script        []Fault
+}
+
+//ChaosTransport is an http.RoundTripper that injects faults into api calls, either from a scripted sequence per
+//command or at random with per command probabilities. Given the same seed and calls it injects the same faults
+type ChaosTransport struct {
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	rules    map[string]*chaosRule
+	delay    time.Duration
+	apiError string
+	injected []InjectedFault
+}
+
+//NewChaosTransport returns a new ChaosTransport that sends api calls with transport, or http.DefaultTransport if
+//it is nil, choosing random faults from the seed
+func NewChaosTransport(transport http.RoundTripper, seed int64) *ChaosTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &ChaosTransport{
+		transport: transport,
+		rand:      rand.New(rand.NewSource(seed)),
+		rules:     make(map[string]*chaosRule),
+		delay:     5 * time.Second,
+		apiError:  "Service temporarily unavailable",
+	}
+}
+
+//SetProbability sets the chance, from 0 to 1, of the fault being injected into calls of the command. An empty
+//command applies to every command without rules of its own
+func (c *ChaosTransport) SetProbability(command string, fault Fault, probability float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rule := c.rule(command)
+	if rule.probabilities == nil {
+		rule.probabilities = make(map[Fault]float64)
+	}
+	rule.probabilities[fault] = probability
+}
+
+//Script queues faults to be injected, in order, into the next calls of the command before any probabilities apply.
+//An empty command applies to every command without rules of its own
+func (c *ChaosTransport) Script(command string, faults ...Fault) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rule := c.rule(command)
+	rule.script = append(rule.script, faults...)
+}
+
+//SetDelay sets how long slow calls are held, and how long timed out calls wait before failing. The default is 5s
+func (c *ChaosTransport) SetDelay(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.delay = d
+}
+
+//SetAPIError sets the message of injected 'error' envelopes
+func (c *ChaosTransport) SetAPIError(message string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.apiError = message
+}
+
+//Injected returns the fault chosen for every api call so far
+func (c *ChaosTransport) Injected() []InjectedFault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]InjectedFault(nil), c.injected...)
+}
+
+//RoundTrip sends the api call, injecting the fault chosen for it
+func (c *ChaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	values, err := readForm(req)
+	if err != nil {
+		return nil, err
+	}
+	command := values.Get("cmd")
+
+	c.mu.Lock()
+	fault := c.choose(command)
+	delay := c.delay
+	apiError := c.apiError
+	c.injected = append(c.injected, InjectedFault{Command: command, Fault: fault})
+	c.mu.Unlock()
+
+	switch fault {
+	case FaultTimeout:
+		if err := wait(req, delay); err != nil {
+			return nil, err
+		}
+		return nil, timeoutError{}
+	case FaultServerError:
+		return response(req, http.StatusServiceUnavailable, []byte(http.StatusText(http.StatusServiceUnavailable))), nil
+	case FaultAPIError:
+		body, _ := json.Marshal(map[string]string{"error": apiError})
+		return response(req, http.StatusOK, body), nil
+	case FaultSlow:
+		if err := wait(req, delay); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil || fault != FaultTruncated {
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	body = body[:len(body)/2]
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Del("Content-Length")
+	return resp, nil
+}
+
+func (c *ChaosTransport) rule(command string) *chaosRule {
+	rule, ok := c.rules[command]
+	if !ok {
+		rule = &chaosRule{}
+		c.rules[command] = rule
+	}
+	return rule
+}
+
+func (c *ChaosTransport) choose(command string) Fault {
+	rule, ok := c.rules[command]
+	if !ok {
+		rule, ok = c.rules[""]
+	}
+	if !ok {
+		return FaultNone
+	}
+
+	if len(rule.script) > 0 {
+		fault := rule.script[0]
+		rule.script = rule.script[1:]
+		return fault
+	}
+
+	faults := make([]Fault, 0, len(rule.probabilities))
+	for fault := range rule.probabilities {
+		faults = append(faults, fault)
+	}
+	sort.Slice(faults, func(i, j int) bool {
+		return faults[i] < faults[j]
+	})
+
+	roll := c.rand.Float64()
+	for _, fault := range faults {
+		roll -= rule.probabilities[fault]
+		if roll < 0 {
+			return fault
+		}
+	}
+	return FaultNone
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "coinpaymentstest: injected timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func wait(req *http.Request, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+func response(req *http.Request, status int, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,156 @@
+package coinpaymentstest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aidenesco/coinpayments"
+)
+
+func chaosClient(t *testing.T, seed int64) (*ChaosTransport, *coinpayments.Client) {
+	t.Helper()
+
+	server := NewServer()
+	t.Cleanup(server.Close)
+	server.SetResult("get_basic_info", map[string]interface{}{"username": "tester"})
+	server.SetResult("get_tx_info", map[string]interface{}{"status": 100})
+
+	chaos := NewChaosTransport(nil, seed)
+	chaos.SetDelay(time.Millisecond)
+	return chaos, server.Client(coinpayments.WithHTTPClient(&http.Client{Transport: chaos}))
+}
+
+func TestChaosTransportScript(t *testing.T) {
+	chaos, client := chaosClient(t, 1)
+	chaos.SetAPIError("Injected failure")
+	chaos.Script("get_basic_info", FaultTimeout, FaultServerError, FaultAPIError, FaultTruncated, FaultSlow)
+
+	tests := []struct {
+		fault     Fault
+		wantError string
+	}{
+		{fault: FaultTimeout, wantError: "injected timeout"},
+		{fault: FaultServerError, wantError: "503"},
+		{fault: FaultAPIError, wantError: "Injected failure"},
+		{fault: FaultTruncated, wantError: "coinpayments:"},
+		{fault: FaultSlow},
+		{fault: FaultNone},
+	}
+
+	for _, test := range tests {
+		_, err := client.GetBasicInfo(&coinpayments.GetBasicInfoRequest{})
+		if test.wantError == "" {
+			if err != nil {
+				t.Errorf("%v: unexpected error %v", test.fault, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.wantError) {
+			t.Errorf("%v: got error %v, want %q", test.fault, err, test.wantError)
+		}
+	}
+
+	if _, err := client.GetTxInfo(&coinpayments.GetTxInfoRequest{TXID: "CP1"}); err != nil {
+		t.Errorf("commands without rules should be untouched: %v", err)
+	}
+
+	injected := chaos.Injected()
+	if len(injected) != len(tests)+1 {
+		t.Fatalf("got %v injected faults, want %v", len(injected), len(tests)+1)
+	}
+	for i, test := range tests {
+		if injected[i] != (InjectedFault{Command: "get_basic_info", Fault: test.fault}) {
+			t.Errorf("injected[%v] = %+v, want %v", i, injected[i], test.fault)
+		}
+	}
+}
+
+func TestChaosTransportTimeoutIsNetError(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	chaos := NewChaosTransport(nil, 1)
+	chaos.SetDelay(0)
+	chaos.Script("", FaultTimeout)
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("cmd=get_basic_info"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	_, err := chaos.RoundTrip(req)
+	if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+		t.Errorf("got error %v, want a net.Error timeout", err)
+	}
+}
+
+func TestChaosTransportHonoursContext(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	chaos := NewChaosTransport(nil, 1)
+	chaos.Script("get_basic_info", FaultSlow)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("cmd=get_basic_info"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	start := time.Now()
+	if _, err := chaos.RoundTrip(req.WithContext(ctx)); err != context.Canceled {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("a cancelled call should not wait for the delay")
+	}
+}
+
+func TestChaosTransportProbabilities(t *testing.T) {
+	run := func(seed int64) []InjectedFault {
+		chaos, client := chaosClient(t, seed)
+		chaos.SetProbability("", FaultServerError, 0.5)
+		chaos.SetProbability("get_tx_info", FaultNone, 1)
+		for i := 0; i < 20; i++ {
+			client.GetBasicInfo(&coinpayments.GetBasicInfoRequest{})
+			client.GetTxInfo(&coinpayments.GetTxInfoRequest{TXID: "CP1"})
+		}
+		return chaos.Injected()
+	}
+
+	first, second := run(42), run(42)
+	if len(first) != len(second) {
+		t.Fatalf("got %v and %v injected faults", len(first), len(second))
+	}
+	failures := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("injected[%v] = %+v and %+v, want the same faults for the same seed", i, first[i], second[i])
+		}
+		if first[i].Command == "get_tx_info" && first[i].Fault != FaultNone {
+			t.Errorf("get_tx_info should use its own rule, got %v", first[i].Fault)
+		}
+		if first[i].Fault == FaultServerError {
+			failures++
+		}
+	}
+	if failures == 0 || failures == 20 {
+		t.Errorf("got %v server errors out of 20 calls at probability 0.5", failures)
+	}
+}
+
+func TestFaultString(t *testing.T) {
+	for fault, want := range map[Fault]string{
+		FaultNone:        "none",
+		FaultTimeout:     "timeout",
+		FaultServerError: "server_error",
+		FaultTruncated:   "truncated",
+		FaultSlow:        "slow",
+		FaultAPIError:    "api_error",
+		Fault(42):        "Fault(42)",
+	} {
+		if got := fault.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", int(fault), got, want)
+		}
+	}
+}
@@ -0,0 +1,1164 @@
+package coinpaymentstest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aidenesco/coinpayments"
+)
+
+//EmulatorOption is an option used to modify an Emulator
+type EmulatorOption func(e *Emulator)
+
+//WithKeys is an option that sets the api keys the Emulator accepts
+func WithKeys(publicKey, privateKey string) EmulatorOption {
+	return func(e *Emulator) {
+		e.publicKey = publicKey
+		e.privateKey = privateKey
+	}
+}
+
+//WithIPNSecret is an option that sets the secret the Emulator signs IPNs with
+func WithIPNSecret(ipnSecret string) EmulatorOption {
+	return func(e *Emulator) {
+		e.ipnSecret = ipnSecret
+	}
+}
+
+//WithMerchantID is an option that sets the merchant id of the emulated account
+func WithMerchantID(merchantID string) EmulatorOption {
+	return func(e *Emulator) {
+		e.merchantID = merchantID
+	}
+}
+
+//WithRate is an option that adds or replaces a coin and its value in BTC
+func WithRate(coin, rateBTC string, fiat bool) EmulatorOption {
+	return func(e *Emulator) {
+		e.coins[strings.ToUpper(coin)] = &emulatorCoin{rateBTC: coinpayments.MustParseAmount(rateBTC), fiat: fiat}
+	}
+}
+
+//WithoutAcceptance is an option that marks a coin as not enabled for acceptance, so that rates called with
+//accepted=2 leave it out. It must come after any WithRate for the same coin
+func WithoutAcceptance(coin string) EmulatorOption {
+	return func(e *Emulator) {
+		if c, ok := e.coins[strings.ToUpper(coin)]; ok {
+			c.unaccepted = true
+		}
+	}
+}
+
+//WithBalance is an option that sets the starting balance of a coin
+func WithBalance(coin, amount string) EmulatorOption {
+	return func(e *Emulator) {
+		e.balances[strings.ToUpper(coin)] = coinpayments.MustParseAmount(amount)
+	}
+}
+
+//WithStartTime is an option that sets the time the emulated clock starts at
+func WithStartTime(t time.Time) EmulatorOption {
+	return func(e *Emulator) {
+		e.now = t
+	}
+}
+
+//WithTransactionTimeout is an option that sets how long a transaction waits for payment before it times out
+func WithTransactionTimeout(d time.Duration) EmulatorOption {
+	return func(e *Emulator) {
+		e.transactionTimeout = d
+	}
+}
+
+//WithConfirmDelay is an option that sets how long a fully paid transaction takes to complete
+func WithConfirmDelay(d time.Duration) EmulatorOption {
+	return func(e *Emulator) {
+		e.confirmDelay = d
+	}
+}
+
+//WithWithdrawalDelay is an option that sets how long a confirmed withdrawal takes to be sent
+func WithWithdrawalDelay(d time.Duration) EmulatorOption {
+	return func(e *Emulator) {
+		e.withdrawalDelay = d
+	}
+}
+
+//WithConversionDelay is an option that sets how long a conversion takes to complete
+func WithConversionDelay(d time.Duration) EmulatorOption {
+	return func(e *Emulator) {
+		e.conversionDelay = d
+	}
+}
+
+//WithIPNClient is an option that sets the http client IPNs are posted with
+func WithIPNClient(client *http.Client) EmulatorOption {
+	return func(e *Emulator) {
+		e.ipnClient = client
+	}
+}
+
+//DeliveredIPN is an IPN the Emulator posted, along with the outcome of posting it
+type DeliveredIPN struct {
+	URL        string
+	Values     map[string]string
+	StatusCode int
+	Err        error
+}
+
+//Emulator is an http.Handler that emulates a coinpayments merchant account. Transactions, withdrawals, transfers and
+//conversions advance through their statuses as the emulated clock is moved on with Advance, balances follow them,
+//and IPNs are posted to each ipn_url as statuses change
+type Emulator struct {
+	publicKey          string
+	privateKey         string
+	ipnSecret          string
+	merchantID         string
+	transactionTimeout time.Duration
+	confirmDelay       time.Duration
+	withdrawalDelay    time.Duration
+	conversionDelay    time.Duration
+	ipnClient          *http.Client
+
+	mu             sync.Mutex
+	now            time.Time
+	coins          map[string]*emulatorCoin
+	balances       map[string]coinpayments.Amount
+	transactions   map[string]*emulatorTransaction
+	withdrawals    map[string]*emulatorWithdrawal
+	conversions    map[string]*emulatorConversion
+	addresses      map[string]*emulatorAddress
+	depositAddress map[string]string
+	delivered      []DeliveredIPN
+	sequence       int
+}
+
+type emulatorCoin struct {
+	rateBTC    coinpayments.Amount
+	fiat       bool
+	unaccepted bool
+}
+
+type emulatorTransaction struct {
+	id         string
+	created    time.Time
+	expires    time.Time
+	paidAt     time.Time
+	status     int
+	statusText string
+	currency1  string
+	currency2  string
+	amount1    coinpayments.Amount
+	amount2    coinpayments.Amount
+	received   coinpayments.Amount
+	confirms   int
+	address    string
+	buyerName  string
+	buyerEmail string
+	itemName   string
+	itemNumber string
+	invoice    string
+	custom     string
+	ipnURL     string
+}
+
+type emulatorWithdrawal struct {
+	id          string
+	created     time.Time
+	confirmedAt time.Time
+	status      int
+	coin        string
+	amount      coinpayments.Amount
+	address     string
+	destTag     string
+	note        string
+	ipnURL      string
+	sendTxID    string
+}
+
+type emulatorConversion struct {
+	id       string
+	created  time.Time
+	status   int
+	from     string
+	to       string
+	sent     coinpayments.Amount
+	received coinpayments.Amount
+}
+
+type emulatorAddress struct {
+	address string
+	coin    string
+	ipnURL  string
+}
+
+type emulatorIPN struct {
+	url     string
+	builder *coinpayments.IPNBuilder
+}
+
+//NewEmulator returns a new Emulator with a BTC, LTC, ETH, LTCT, USD and EUR rate table and empty balances
+func NewEmulator(options ...EmulatorOption) *Emulator {
+	e := &Emulator{
+		publicKey:          PublicKey,
+		privateKey:         PrivateKey,
+		ipnSecret:          "coinpaymentstest-ipn-secret",
+		merchantID:         "coinpaymentstest-merchant",
+		transactionTimeout: 2 * time.Hour,
+		confirmDelay:       10 * time.Minute,
+		withdrawalDelay:    5 * time.Minute,
+		conversionDelay:    5 * time.Minute,
+		ipnClient:          http.DefaultClient,
+		now:                time.Now(),
+		coins: map[string]*emulatorCoin{
+			"BTC":  {rateBTC: coinpayments.MustParseAmount("1")},
+			"LTC":  {rateBTC: coinpayments.MustParseAmount("0.004")},
+			"LTCT": {rateBTC: coinpayments.MustParseAmount("0.004")},
+			"ETH":  {rateBTC: coinpayments.MustParseAmount("0.05")},
+			"USD":  {rateBTC: coinpayments.MustParseAmount("0.00002"), fiat: true},
+			"EUR":  {rateBTC: coinpayments.MustParseAmount("0.000022"), fiat: true},
+		},
+		balances:       make(map[string]coinpayments.Amount),
+		transactions:   make(map[string]*emulatorTransaction),
+		withdrawals:    make(map[string]*emulatorWithdrawal),
+		conversions:    make(map[string]*emulatorConversion),
+		addresses:      make(map[string]*emulatorAddress),
+		depositAddress: make(map[string]string),
+	}
+
+	for _, o := range options {
+		o(e)
+	}
+	return e
+}
+
+//Client returns a Client that calls the Emulator served at apiURL with its keys
+func (e *Emulator) Client(apiURL string, options ...coinpayments.ClientOption) *coinpayments.Client {
+	options = append([]coinpayments.ClientOption{coinpayments.WithAPIURL(apiURL)}, options...)
+	return coinpayments.NewClient(e.publicKey, e.privateKey, options...)
+}
+
+//IPNSecret returns the secret the Emulator signs IPNs with
+func (e *Emulator) IPNSecret() string {
+	return e.ipnSecret
+}
+
+//MerchantID returns the merchant id of the emulated account
+func (e *Emulator) MerchantID() string {
+	return e.merchantID
+}
+
+//Now returns the emulated time
+func (e *Emulator) Now() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.now
+}
+
+//Advance moves the emulated clock on, completing, timing out and sending everything that falls due
+func (e *Emulator) Advance(d time.Duration) {
+	e.mu.Lock()
+	e.now = e.now.Add(d)
+	ipns := e.tick()
+	e.mu.Unlock()
+
+	e.deliver(ipns)
+}
+
+//Balance returns the balance of a coin
+func (e *Emulator) Balance(coin string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.balance(coin).StringFixed(8)
+}
+
+//SetBalance sets the balance of a coin
+func (e *Emulator) SetBalance(coin, amount string) error {
+	value, err := coinpayments.ParseAmount(amount)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.balances[strings.ToUpper(coin)] = value
+	return nil
+}
+
+//Pay pays an amount of the transaction's currency2 towards it. A partial payment leaves the transaction waiting,
+//and a full payment starts its confirmation
+func (e *Emulator) Pay(txnID, amount string) error {
+	value, err := coinpayments.ParseAmount(amount)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	txn, ok := e.transactions[txnID]
+	if !ok {
+		e.mu.Unlock()
+		return fmt.Errorf("coinpaymentstest: no transaction %q", txnID)
+	}
+	if txn.status != 0 {
+		e.mu.Unlock()
+		return fmt.Errorf("coinpaymentstest: transaction %q is not waiting for funds", txnID)
+	}
+
+	txn.received = txn.received.Add(value)
+	if txn.received.Cmp(txn.amount2) >= 0 {
+		txn.status = 1
+		txn.statusText = "We have confirmed coin reception from the buyer"
+		txn.paidAt = e.now
+	} else {
+		txn.statusText = fmt.Sprintf("Waiting for buyer funds... (%v/%v %v received)", txn.received.StringFixed(8), txn.amount2.StringFixed(8), txn.currency2)
+	}
+
+	ipns := append(e.transactionIPN(txn), e.tick()...)
+	e.mu.Unlock()
+
+	e.deliver(ipns)
+	return nil
+}
+
+//Deposit receives an amount of a coin to an address returned by get_deposit_address or get_callback_address
+func (e *Emulator) Deposit(address, amount string) error {
+	value, err := coinpayments.ParseAmount(amount)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	addr, ok := e.addresses[address]
+	if !ok {
+		e.mu.Unlock()
+		return fmt.Errorf("coinpaymentstest: no address %q", address)
+	}
+
+	e.credit(addr.coin, value)
+
+	var ipns []emulatorIPN
+	if addr.ipnURL != "" {
+		ipns = append(ipns, emulatorIPN{
+			url: addr.ipnURL,
+			builder: e.ipn("deposit").
+				Set("txn_id", e.newID("")).
+				Set("address", addr.address).
+				Set("status", "100").
+				Set("status_text", "Deposit confirmed").
+				Set("currency", addr.coin).
+				Set("confirms", "3").
+				Set("amount", value.StringFixed(8)).
+				Set("amounti", strconv.FormatInt(value.Satoshis(), 10)).
+				Set("fee", "0.00000000").
+				Set("feei", "0"),
+		})
+	}
+	e.mu.Unlock()
+
+	e.deliver(ipns)
+	return nil
+}
+
+//ConfirmWithdrawal confirms a withdrawal or transfer created without auto_confirm, as the emailed link would
+func (e *Emulator) ConfirmWithdrawal(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	w, ok := e.withdrawals[id]
+	if !ok {
+		return fmt.Errorf("coinpaymentstest: no withdrawal %q", id)
+	}
+	if w.status != 0 {
+		return fmt.Errorf("coinpaymentstest: withdrawal %q does not need confirming", id)
+	}
+	w.status = 1
+	w.confirmedAt = e.now
+	return nil
+}
+
+//Delivered returns every IPN the Emulator has posted
+func (e *Emulator) Delivered() []DeliveredIPN {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]DeliveredIPN(nil), e.delivered...)
+}
+
+//ControlHandler returns an http.Handler that drives the Emulator over http, for when it runs as a standalone binary.
+//It serves POST /advance?duration=, /pay?txn_id=&amount=, /deposit?address=&amount=, /confirm_withdrawal?id=
+//and /balance?coin=&amount=, and GET /balances and /ipns
+func (e *Emulator) ControlHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/advance", e.control(func(values url.Values) error {
+		d, err := time.ParseDuration(values.Get("duration"))
+		if err != nil {
+			return err
+		}
+		e.Advance(d)
+		return nil
+	}))
+	mux.HandleFunc("/pay", e.control(func(values url.Values) error {
+		return e.Pay(values.Get("txn_id"), values.Get("amount"))
+	}))
+	mux.HandleFunc("/deposit", e.control(func(values url.Values) error {
+		return e.Deposit(values.Get("address"), values.Get("amount"))
+	}))
+	mux.HandleFunc("/confirm_withdrawal", e.control(func(values url.Values) error {
+		return e.ConfirmWithdrawal(values.Get("id"))
+	}))
+	mux.HandleFunc("/balance", e.control(func(values url.Values) error {
+		return e.SetBalance(values.Get("coin"), values.Get("amount"))
+	}))
+	mux.HandleFunc("/balances", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		balances := e.balancesResult(true)
+		e.mu.Unlock()
+		writeJSON(w, balances)
+	})
+	mux.HandleFunc("/ipns", func(w http.ResponseWriter, r *http.Request) {
+		var ipns []map[string]interface{}
+		for _, ipn := range e.Delivered() {
+			delivered := map[string]interface{}{
+				"url":         ipn.URL,
+				"values":      ipn.Values,
+				"status_code": ipn.StatusCode,
+			}
+			if ipn.Err != nil {
+				delivered["error"] = ipn.Err.Error()
+			}
+			ipns = append(ipns, delivered)
+		}
+		writeJSON(w, ipns)
+	})
+	return mux
+}
+
+func (e *Emulator) control(fn func(values url.Values) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := fn(r.Form); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"now": e.Now().Unix()})
+	}
+}
+
+//ServeHTTP answers api calls for the emulated account
+func (e *Emulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	values, message, ok := readCall(w, r, e.publicKey, e.privateKey)
+	if values == nil {
+		return
+	}
+	if !ok {
+		writeError(w, message)
+		return
+	}
+
+	e.mu.Lock()
+	result, message, ipns := e.command(values.Get("cmd"), values)
+	e.mu.Unlock()
+
+	if message != "" {
+		writeError(w, message)
+	} else {
+		writeResult(w, result)
+	}
+
+	e.deliver(ipns)
+}
+
+func (e *Emulator) command(cmd string, values url.Values) (interface{}, string, []emulatorIPN) {
+	switch cmd {
+	case "get_basic_info":
+		return map[string]interface{}{
+			"username":    "coinpaymentstest",
+			"merchant_id": e.merchantID,
+			"email":       "merchant@example.com",
+			"public_name": "coinpaymentstest",
+		}, "", nil
+	case "rates":
+		return e.rates(values), "", nil
+	case "balances":
+		return e.balancesResult(values.Get("all") == "1"), "", nil
+	case "get_deposit_address":
+		return e.getDepositAddress(values)
+	case "get_callback_address":
+		return e.getCallbackAddress(values)
+	case "create_transaction":
+		return e.createTransaction(values)
+	case "get_tx_info":
+		return e.getTxInfo(values)
+	case "get_tx_info_multi":
+		return e.getTxInfoMulti(values)
+	case "get_tx_ids":
+		return e.getTxIDs(values)
+	case "create_transfer":
+		return e.createTransfer(values)
+	case "create_withdrawal":
+		return e.createWithdrawal(values)
+	case "get_withdrawal_info":
+		return e.getWithdrawalInfo(values)
+	case "get_withdrawal_history":
+		return e.getWithdrawalHistory(values)
+	case "convert":
+		return e.convert(values)
+	case "convert_limits":
+		return e.convertLimits(values)
+	case "get_conversion_info":
+		return e.getConversionInfo(values)
+	}
+	return nil, fmt.Sprintf("Unknown command %q", cmd), nil
+}
+
+func (e *Emulator) rates(values url.Values) map[string]interface{} {
+	accepted := values.Get("accepted")
+	result := make(map[string]interface{})
+	for code, coin := range e.coins {
+		if accepted == "2" && !coin.fiat && coin.unaccepted {
+			continue
+		}
+
+		rate := map[string]interface{}{
+			"is_fiat":     boolInt(coin.fiat),
+			"rate_btc":    coin.rateBTC.StringFixed(12),
+			"last_update": strconv.FormatInt(e.now.Unix(), 10),
+			"tx_fee":      "0.00000000",
+			"status":      "online",
+		}
+		if accepted == "1" || accepted == "2" {
+			rate["accepted"] = boolInt(!coin.fiat && !coin.unaccepted)
+		}
+		if values.Get("short") != "1" {
+			rate["name"] = code
+			rate["confirms"] = "3"
+			capabilities := []string{}
+			if !coin.fiat {
+				capabilities = []string{"payments", "wallet", "transfers", "convert"}
+			}
+			rate["capabilities"] = capabilities
+		}
+		result[code] = rate
+	}
+	return result
+}
+
+func (e *Emulator) balancesResult(all bool) map[string]interface{} {
+	result := make(map[string]interface{})
+	for code, coin := range e.coins {
+		balance := e.balance(code)
+		if coin.fiat || (!all && balance.Sign() == 0) {
+			continue
+		}
+		result[code] = map[string]interface{}{
+			"balance":  balance.Satoshis(),
+			"balancef": balance.StringFixed(8),
+			"status":   "available",
+		}
+	}
+	return result
+}
+
+func (e *Emulator) getDepositAddress(values url.Values) (interface{}, string, []emulatorIPN) {
+	coin, message := e.cryptoCoin(values.Get("currency"))
+	if message != "" {
+		return nil, message, nil
+	}
+
+	address, ok := e.depositAddress[coin]
+	if !ok {
+		address = e.newAddress(coin, "")
+		e.depositAddress[coin] = address
+	}
+	return map[string]interface{}{"address": address}, "", nil
+}
+
+func (e *Emulator) getCallbackAddress(values url.Values) (interface{}, string, []emulatorIPN) {
+	coin, message := e.cryptoCoin(values.Get("currency"))
+	if message != "" {
+		return nil, message, nil
+	}
+
+	return map[string]interface{}{"address": e.newAddress(coin, values.Get("ipn_url"))}, "", nil
+}
+
+func (e *Emulator) createTransaction(values url.Values) (interface{}, string, []emulatorIPN) {
+	amount, err := coinpayments.ParseAmount(values.Get("amount"))
+	if err != nil || amount.Sign() <= 0 {
+		return nil, "Invalid amount!", nil
+	}
+	currency1, message := e.knownCoin(values.Get("currency1"))
+	if message != "" {
+		return nil, message, nil
+	}
+	currency2, message := e.cryptoCoin(values.Get("currency2"))
+	if message != "" {
+		return nil, message, nil
+	}
+
+	txn := &emulatorTransaction{
+		id:         e.newID("CPTEST"),
+		created:    e.now,
+		expires:    e.now.Add(e.transactionTimeout),
+		statusText: "Waiting for buyer funds...",
+		currency1:  currency1,
+		currency2:  currency2,
+		amount1:    amount,
+		amount2:    e.convertAmount(amount, currency1, currency2),
+		address:    e.newAddress(currency2, ""),
+		buyerName:  values.Get("buyer_name"),
+		buyerEmail: values.Get("buyer_email"),
+		itemName:   values.Get("item_name"),
+		itemNumber: values.Get("item_number"),
+		invoice:    values.Get("invoice"),
+		custom:     values.Get("custom"),
+		ipnURL:     values.Get("ipn_url"),
+	}
+	e.transactions[txn.id] = txn
+
+	return map[string]interface{}{
+		"amount":          txn.amount2.StringFixed(8),
+		"address":         txn.address,
+		"txn_id":          txn.id,
+		"confirms_needed": "3",
+		"timeout":         int(e.transactionTimeout / time.Second),
+		"checkout_url":    "https://www.coinpayments.net/index.php?cmd=checkout&id=" + txn.id,
+		"status_url":      "https://www.coinpayments.net/index.php?cmd=status&id=" + txn.id,
+		"qrcode_url":      "https://www.coinpayments.net/qrgen.php?id=" + txn.id,
+	}, "", nil
+}
+
+func (e *Emulator) getTxInfo(values url.Values) (interface{}, string, []emulatorIPN) {
+	txn, ok := e.transactions[values.Get("txid")]
+	if !ok {
+		return nil, "Invalid payment ID!", nil
+	}
+
+	info := e.txInfo(txn)
+	if values.Get("full") == "1" {
+		info["checkout"] = map[string]interface{}{
+			"currency":    txn.currency1,
+			"item_number": txn.itemNumber,
+			"item_name":   txn.itemName,
+			"invoice":     txn.invoice,
+			"custom":      txn.custom,
+			"ipn_url":     txn.ipnURL,
+		}
+	}
+	return info, "", nil
+}
+
+func (e *Emulator) getTxInfoMulti(values url.Values) (interface{}, string, []emulatorIPN) {
+	result := make(map[string]interface{})
+	for _, id := range strings.Split(values.Get("txid"), "|") {
+		txn, ok := e.transactions[id]
+		if !ok {
+			result[id] = map[string]interface{}{"error": "Invalid payment ID!"}
+			continue
+		}
+		info := e.txInfo(txn)
+		info["error"] = "ok"
+		result[id] = info
+	}
+	return result, "", nil
+}
+
+func (e *Emulator) txInfo(txn *emulatorTransaction) map[string]interface{} {
+	return map[string]interface{}{
+		"time_created":    txn.created.Unix(),
+		"time_expires":    txn.expires.Unix(),
+		"status":          txn.status,
+		"status_text":     txn.statusText,
+		"type":            "coins",
+		"coin":            txn.currency2,
+		"amount":          txn.amount2.Satoshis(),
+		"amountf":         txn.amount2.StringFixed(8),
+		"received":        txn.received.Satoshis(),
+		"receivedf":       txn.received.StringFixed(8),
+		"recv_confirms":   txn.confirms,
+		"payment_address": txn.address,
+	}
+}
+
+func (e *Emulator) getTxIDs(values url.Values) (interface{}, string, []emulatorIPN) {
+	var txns []*emulatorTransaction
+	for _, txn := range e.transactions {
+		txns = append(txns, txn)
+	}
+	sort.Slice(txns, func(i, j int) bool {
+		return txns[i].created.After(txns[j].created) || (txns[i].created.Equal(txns[j].created) && txns[i].id > txns[j].id)
+	})
+
+	newer, _ := strconv.ParseInt(values.Get("newer"), 10, 64)
+	var ids []string
+	for _, txn := range txns {
+		if txn.created.Unix() >= newer {
+			ids = append(ids, txn.id)
+		}
+	}
+	return page(ids, values), "", nil
+}
+
+func (e *Emulator) createTransfer(values url.Values) (interface{}, string, []emulatorIPN) {
+	amount, err := coinpayments.ParseAmount(values.Get("amount"))
+	if err != nil || amount.Sign() <= 0 {
+		return nil, "Invalid amount!", nil
+	}
+	coin, message := e.cryptoCoin(values.Get("currency"))
+	if message != "" {
+		return nil, message, nil
+	}
+	if values.Get("merchant") == "" && values.Get("pbntag") == "" {
+		return nil, "You must specify a merchant ID or $PayByName tag!", nil
+	}
+	if !e.debit(coin, amount) {
+		return nil, "Insufficient funds!", nil
+	}
+
+	//transfers are withdrawals to another coinpayments account, so they share the withdrawal ids and statuses
+	transfer := &emulatorWithdrawal{
+		id:      e.newID("CTEST"),
+		created: e.now,
+		coin:    coin,
+		amount:  amount,
+		address: values.Get("merchant"),
+		note:    values.Get("note"),
+	}
+	if transfer.address == "" {
+		transfer.address = values.Get("pbntag")
+	}
+	if values.Get("auto_confirm") == "1" {
+		transfer.status = 1
+		transfer.confirmedAt = e.now
+	}
+	e.withdrawals[transfer.id] = transfer
+
+	return map[string]interface{}{"id": transfer.id, "status": transfer.status}, "", nil
+}
+
+func (e *Emulator) createWithdrawal(values url.Values) (interface{}, string, []emulatorIPN) {
+	coin, message := e.cryptoCoin(values.Get("currency"))
+	if message != "" {
+		return nil, message, nil
+	}
+	amount, err := coinpayments.ParseAmount(values.Get("amount"))
+	if err != nil || amount.Sign() <= 0 {
+		return nil, "Invalid amount!", nil
+	}
+	if currency2 := values.Get("currency2"); currency2 != "" {
+		from, message := e.knownCoin(currency2)
+		if message != "" {
+			return nil, message, nil
+		}
+		amount = e.convertAmount(amount, from, coin)
+	}
+	if values.Get("address") == "" && values.Get("pbntag") == "" {
+		return nil, "You must specify an address or $PayByName tag!", nil
+	}
+	if values.Get("address") != "" && values.Get("pbntag") != "" {
+		return nil, "You cannot specify both an address and a $PayByName tag!", nil
+	}
+	if !e.debit(coin, amount) {
+		return nil, "Insufficient funds!", nil
+	}
+
+	w := &emulatorWithdrawal{
+		id:      e.newID("CWTEST"),
+		created: e.now,
+		coin:    coin,
+		amount:  amount,
+		address: values.Get("address"),
+		destTag: values.Get("dest_tag"),
+		note:    values.Get("note"),
+		ipnURL:  values.Get("ipn_url"),
+	}
+	if w.address == "" {
+		w.address = values.Get("pbntag")
+	}
+	if values.Get("auto_confirm") == "1" {
+		w.status = 1
+		w.confirmedAt = e.now
+	}
+	e.withdrawals[w.id] = w
+
+	return map[string]interface{}{
+		"id":     w.id,
+		"status": w.status,
+		"amount": w.amount.StringFixed(8),
+	}, "", nil
+}
+
+func (e *Emulator) getWithdrawalInfo(values url.Values) (interface{}, string, []emulatorIPN) {
+	w, ok := e.withdrawals[values.Get("id")]
+	if !ok {
+		return nil, "Invalid withdrawal ID!", nil
+	}
+	return e.withdrawalInfo(w), "", nil
+}
+
+func (e *Emulator) getWithdrawalHistory(values url.Values) (interface{}, string, []emulatorIPN) {
+	var withdrawals []*emulatorWithdrawal
+	newer, _ := strconv.ParseInt(values.Get("newer"), 10, 64)
+	for _, w := range e.withdrawals {
+		if w.created.Unix() >= newer {
+			withdrawals = append(withdrawals, w)
+		}
+	}
+	sort.Slice(withdrawals, func(i, j int) bool {
+		return withdrawals[i].created.After(withdrawals[j].created) || (withdrawals[i].created.Equal(withdrawals[j].created) && withdrawals[i].id > withdrawals[j].id)
+	})
+
+	var history []interface{}
+	for _, w := range withdrawals {
+		info := e.withdrawalInfo(w)
+		info["id"] = w.id
+		info["send_dest_tag"] = w.destTag
+		history = append(history, info)
+	}
+	return page(history, values), "", nil
+}
+
+func (e *Emulator) withdrawalInfo(w *emulatorWithdrawal) map[string]interface{} {
+	return map[string]interface{}{
+		"time_created": w.created.Unix(),
+		"status":       w.status,
+		"status_text":  withdrawalStatusText(w.status),
+		"coin":         w.coin,
+		"amount":       w.amount.Satoshis(),
+		"amountf":      w.amount.StringFixed(8),
+		"note":         w.note,
+		"send_address": w.address,
+		"send_txid":    w.sendTxID,
+	}
+}
+
+func (e *Emulator) convert(values url.Values) (interface{}, string, []emulatorIPN) {
+	amount, err := coinpayments.ParseAmount(values.Get("amount"))
+	if err != nil || amount.Sign() <= 0 {
+		return nil, "Invalid amount!", nil
+	}
+	from, message := e.cryptoCoin(values.Get("from"))
+	if message != "" {
+		return nil, message, nil
+	}
+	to, message := e.cryptoCoin(values.Get("to"))
+	if message != "" {
+		return nil, message, nil
+	}
+	if !e.debit(from, amount) {
+		return nil, "Insufficient funds!", nil
+	}
+
+	conversion := &emulatorConversion{
+		id:       e.newID("CCTEST"),
+		created:  e.now,
+		from:     from,
+		to:       to,
+		sent:     amount,
+		received: e.convertAmount(amount, from, to),
+	}
+	e.conversions[conversion.id] = conversion
+
+	return map[string]interface{}{"id": conversion.id}, "", nil
+}
+
+func (e *Emulator) convertLimits(values url.Values) (interface{}, string, []emulatorIPN) {
+	from, message := e.cryptoCoin(values.Get("from"))
+	if message != "" {
+		return nil, message, nil
+	}
+	if _, message := e.cryptoCoin(values.Get("to")); message != "" {
+		return nil, message, nil
+	}
+
+	return map[string]interface{}{
+		"min": e.convertAmount(coinpayments.MustParseAmount("0.0001"), "BTC", from).StringFixed(8),
+		"max": e.convertAmount(coinpayments.MustParseAmount("10"), "BTC", from).StringFixed(8),
+	}, "", nil
+}
+
+func (e *Emulator) getConversionInfo(values url.Values) (interface{}, string, []emulatorIPN) {
+	conversion, ok := e.conversions[values.Get("id")]
+	if !ok {
+		return nil, "Invalid conversion ID!", nil
+	}
+
+	statusText := "Pending"
+	if conversion.status == 2 {
+		statusText = "Complete"
+	}
+	return map[string]interface{}{
+		"time_created": strconv.FormatInt(conversion.created.Unix(), 10),
+		"status":       conversion.status,
+		"status_text":  statusText,
+		"coin1":        conversion.from,
+		"coin2":        conversion.to,
+		"amount_sent":  conversion.sent.Satoshis(),
+		"amount_sentf": conversion.sent.StringFixed(8),
+		"received":     conversion.received.Satoshis(),
+		"receivedf":    conversion.received.StringFixed(8),
+	}, "", nil
+}
+
+//tick completes, times out and sends everything that is due at the current emulated time
+func (e *Emulator) tick() []emulatorIPN {
+	var ipns []emulatorIPN
+
+	for _, txn := range e.sortedTransactions() {
+		switch {
+		case txn.status == 1 && !e.now.Before(txn.paidAt.Add(e.confirmDelay)):
+			txn.status = 100
+			txn.statusText = "Complete"
+			txn.confirms = 3
+			e.credit(txn.currency2, txn.received)
+			ipns = append(ipns, e.transactionIPN(txn)...)
+		case txn.status == 0 && !e.now.Before(txn.expires):
+			txn.status = -1
+			txn.statusText = "Cancelled / Timed Out"
+			ipns = append(ipns, e.transactionIPN(txn)...)
+		}
+	}
+
+	for _, w := range e.sortedWithdrawals() {
+		if w.status == 1 && !e.now.Before(w.confirmedAt.Add(e.withdrawalDelay)) {
+			w.status = 2
+			w.sendTxID = e.newID("")
+			if w.ipnURL != "" {
+				ipns = append(ipns, emulatorIPN{
+					url: w.ipnURL,
+					builder: e.ipn("withdrawal").
+						Set("id", w.id).
+						Set("status", "2").
+						Set("status_text", withdrawalStatusText(w.status)).
+						Set("address", w.address).
+						Set("txn_id", w.sendTxID).
+						Set("currency", w.coin).
+						Set("amount", w.amount.StringFixed(8)).
+						Set("amounti", strconv.FormatInt(w.amount.Satoshis(), 10)),
+				})
+			}
+		}
+	}
+
+	for _, conversion := range e.sortedConversions() {
+		if conversion.status == 0 && !e.now.Before(conversion.created.Add(e.conversionDelay)) {
+			conversion.status = 2
+			e.credit(conversion.to, conversion.received)
+		}
+	}
+
+	return ipns
+}
+
+func (e *Emulator) sortedTransactions() []*emulatorTransaction {
+	txns := make([]*emulatorTransaction, 0, len(e.transactions))
+	for _, txn := range e.transactions {
+		txns = append(txns, txn)
+	}
+	sort.Slice(txns, func(i, j int) bool {
+		return txns[i].id < txns[j].id
+	})
+	return txns
+}
+
+func (e *Emulator) sortedWithdrawals() []*emulatorWithdrawal {
+	withdrawals := make([]*emulatorWithdrawal, 0, len(e.withdrawals))
+	for _, w := range e.withdrawals {
+		withdrawals = append(withdrawals, w)
+	}
+	sort.Slice(withdrawals, func(i, j int) bool {
+		return withdrawals[i].id < withdrawals[j].id
+	})
+	return withdrawals
+}
+
+func (e *Emulator) sortedConversions() []*emulatorConversion {
+	conversions := make([]*emulatorConversion, 0, len(e.conversions))
+	for _, conversion := range e.conversions {
+		conversions = append(conversions, conversion)
+	}
+	sort.Slice(conversions, func(i, j int) bool {
+		return conversions[i].id < conversions[j].id
+	})
+	return conversions
+}
+
+func (e *Emulator) transactionIPN(txn *emulatorTransaction) []emulatorIPN {
+	if txn.ipnURL == "" {
+		return nil
+	}
+
+	return []emulatorIPN{{
+		url: txn.ipnURL,
+		builder: e.ipn("api").
+			Set("status", strconv.Itoa(txn.status)).
+			Set("status_text", txn.statusText).
+			Set("txn_id", txn.id).
+			Set("currency1", txn.currency1).
+			Set("currency2", txn.currency2).
+			Set("amount1", txn.amount1.StringFixed(8)).
+			Set("amount2", txn.amount2.StringFixed(8)).
+			Set("fee", "0.00000000").
+			Set("buyer_name", txn.buyerName).
+			Set("email", txn.buyerEmail).
+			Set("item_name", txn.itemName).
+			Set("item_number", txn.itemNumber).
+			Set("invoice", txn.invoice).
+			Set("custom", txn.custom).
+			Set("received_amount", txn.received.StringFixed(8)).
+			Set("received_confirms", strconv.Itoa(txn.confirms)),
+	}}
+}
+
+func (e *Emulator) ipn(ipnType string) *coinpayments.IPNBuilder {
+	return coinpayments.NewIPNBuilder(ipnType).Set("merchant", e.merchantID)
+}
+
+func (e *Emulator) deliver(ipns []emulatorIPN) {
+	for _, ipn := range ipns {
+		delivered := DeliveredIPN{
+			URL:    ipn.url,
+			Values: make(map[string]string),
+		}
+		values := ipn.builder.Values()
+		for k := range values {
+			delivered.Values[k] = values.Get(k)
+		}
+
+		resp, err := ipn.builder.Post(e.ipnClient, ipn.url, e.ipnSecret)
+		if err != nil {
+			delivered.Err = err
+		} else {
+			delivered.StatusCode = resp.StatusCode
+			resp.Body.Close()
+		}
+
+		e.mu.Lock()
+		e.delivered = append(e.delivered, delivered)
+		e.mu.Unlock()
+	}
+}
+
+func (e *Emulator) knownCoin(code string) (string, string) {
+	code = strings.ToUpper(code)
+	if _, ok := e.coins[code]; !ok {
+		return "", fmt.Sprintf("Unknown coin %q", code)
+	}
+	return code, ""
+}
+
+func (e *Emulator) cryptoCoin(code string) (string, string) {
+	code, message := e.knownCoin(code)
+	if message != "" {
+		return "", message
+	}
+	if e.coins[code].fiat {
+		return "", fmt.Sprintf("%v is not a cryptocurrency", code)
+	}
+	return code, ""
+}
+
+//convertAmount converts between coins at their BTC rates, rounding to 8 decimal places as coinpayments does
+func (e *Emulator) convertAmount(amount coinpayments.Amount, from, to string) coinpayments.Amount {
+	value, _ := new(big.Rat).SetString(amount.Mul(e.coins[from].rateBTC).String())
+	rate, _ := new(big.Rat).SetString(e.coins[to].rateBTC.String())
+	return coinpayments.MustParseAmount(value.Quo(value, rate).FloatString(8))
+}
+
+func (e *Emulator) balance(coin string) coinpayments.Amount {
+	return e.balances[strings.ToUpper(coin)]
+}
+
+func (e *Emulator) credit(coin string, amount coinpayments.Amount) {
+	e.balances[coin] = e.balance(coin).Add(amount)
+}
+
+func (e *Emulator) debit(coin string, amount coinpayments.Amount) bool {
+	balance := e.balance(coin)
+	if balance.LessThan(amount) {
+		return false
+	}
+	e.balances[coin] = balance.Sub(amount)
+	return true
+}
+
+func (e *Emulator) newAddress(coin, ipnURL string) string {
+	address := fmt.Sprintf("%v-%v", strings.ToLower(coin), e.newID(""))
+	e.addresses[address] = &emulatorAddress{address: address, coin: coin, ipnURL: ipnURL}
+	return address
+}
+
+func (e *Emulator) newID(prefix string) string {
+	e.sequence++
+
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%v%08d", prefix, e.sequence)
+	}
+	return fmt.Sprintf("%v%08d%X", prefix, e.sequence, b)
+}
+
+func page(items interface{}, values url.Values) interface{} {
+	limit, err := strconv.Atoi(values.Get("limit"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 25
+	}
+	start, _ := strconv.Atoi(values.Get("start"))
+
+	switch items := items.(type) {
+	case []string:
+		if start > len(items) {
+			start = len(items)
+		}
+		if start+limit < len(items) {
+			return items[start : start+limit]
+		}
+		return append([]string{}, items[start:]...)
+	case []interface{}:
+		if start > len(items) {
+			start = len(items)
+		}
+		if start+limit < len(items) {
+			return items[start : start+limit]
+		}
+		return append([]interface{}{}, items[start:]...)
+	}
+	return items
+}
+
+func withdrawalStatusText(status int) string {
+	switch status {
+	case 0:
+		return "Waiting for email confirmation"
+	case 1:
+		return "Pending"
+	case 2:
+		return "Complete"
+	}
+	return "Cancelled"
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
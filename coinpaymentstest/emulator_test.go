@@ -0,0 +1,429 @@
+package coinpaymentstest
+
+import (
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aidenesco/coinpayments"
+)
+
+//ipnRecorder verifies the IPNs the Emulator posts and keeps them in order
+type ipnRecorder struct {
+	mu   sync.Mutex
+	ipns []*coinpayments.IPN
+}
+
+func (r *ipnRecorder) statuses() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statuses := make([]string, len(r.ipns))
+	for i, ipn := range r.ipns {
+		api, err := ipn.ToApiIPN()
+		if err != nil {
+			withdrawal, _ := ipn.ToWithdrawalIPN()
+			statuses[i] = "withdrawal " + withdrawal.Status.String()
+			continue
+		}
+		statuses[i] = "api " + api.Status.String()
+	}
+	return statuses
+}
+
+func startEmulator(t *testing.T, options ...EmulatorOption) (*Emulator, *coinpayments.Client, *ipnRecorder, string) {
+	t.Helper()
+
+	emulator := NewEmulator(append([]EmulatorOption{WithStartTime(time.Unix(1600000000, 0))}, options...)...)
+	api := httptest.NewServer(emulator)
+	t.Cleanup(api.Close)
+	client := emulator.Client(api.URL)
+
+	recorder := &ipnRecorder{}
+	handler := coinpayments.NewIPNHandler(client, emulator.IPNSecret(), coinpayments.WithMerchantID(emulator.MerchantID()), coinpayments.WithStrict())
+	handler.OnUnhandled(func(ipn *coinpayments.IPN) error {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+
+		recorder.ipns = append(recorder.ipns, ipn)
+		return nil
+	})
+	ipnServer := httptest.NewServer(handler)
+	t.Cleanup(ipnServer.Close)
+
+	return emulator, client, recorder, ipnServer.URL
+}
+
+func assertStrings(t *testing.T, what string, got []string, want ...string) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Errorf("%v = %q, want %q", what, got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%v = %q, want %q", what, got, want)
+			return
+		}
+	}
+}
+
+func TestEmulatorTransaction(t *testing.T) {
+	emulator, client, recorder, ipnURL := startEmulator(t)
+
+	created, err := client.CreateTransaction(&coinpayments.CreateTransactionRequest{
+		Amount:     coinpayments.MustParseAmount("10"),
+		Currency1:  "USD",
+		Currency2:  "BTC",
+		BuyerEmail: "buyer@example.com",
+		IPNURL:     ipnURL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := created.Amount.String(); got != "0.0002" {
+		t.Fatalf("amount = %v, want 0.0002", got)
+	}
+	if created.Timeout.Duration != 2*time.Hour {
+		t.Errorf("Timeout = %v, want 2h", created.Timeout.Duration)
+	}
+	txnID := string(created.TxnId)
+
+	if err := emulator.Pay(txnID, "0.0001"); err != nil {
+		t.Fatal(err)
+	}
+	if err := emulator.Pay(txnID, "0.0001"); err != nil {
+		t.Fatal(err)
+	}
+	if err := emulator.Pay(txnID, "0.0001"); err == nil {
+		t.Error("expected an error paying a transaction that is no longer waiting")
+	}
+
+	emulator.Advance(10 * time.Minute)
+
+	info, err := client.GetTxInfo(&coinpayments.GetTxInfoRequest{TXID: txnID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Status.IsComplete() || info.Receivedf.String() != "0.0002" {
+		t.Errorf("unexpected tx info status %v received %v", info.Status, info.Receivedf)
+	}
+	if got := emulator.Balance("BTC"); got != "0.00020000" {
+		t.Errorf("BTC balance = %v, want 0.00020000", got)
+	}
+
+	assertStrings(t, "ipns", recorder.statuses(), "api waiting", "api confirmed", "api complete")
+	for _, delivered := range emulator.Delivered() {
+		if delivered.Err != nil || delivered.StatusCode != 200 || delivered.URL != ipnURL {
+			t.Errorf("unexpected delivery %+v", delivered)
+		}
+	}
+}
+
+func TestEmulatorTransactionTimeout(t *testing.T) {
+	emulator, client, recorder, ipnURL := startEmulator(t, WithTransactionTimeout(time.Hour))
+
+	created, err := client.CreateTransaction(&coinpayments.CreateTransactionRequest{
+		Amount:     coinpayments.MustParseAmount("1"),
+		Currency1:  "LTC",
+		Currency2:  "LTC",
+		BuyerEmail: "buyer@example.com",
+		IPNURL:     ipnURL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emulator.Advance(59 * time.Minute)
+	assertStrings(t, "ipns before the timeout", recorder.statuses())
+
+	emulator.Advance(time.Minute)
+	assertStrings(t, "ipns after the timeout", recorder.statuses(), "api cancelled")
+
+	if err := emulator.Pay(string(created.TxnId), "1"); err == nil {
+		t.Error("expected an error paying a timed out transaction")
+	}
+}
+
+func TestEmulatorPartialPaymentTimeout(t *testing.T) {
+	emulator, client, recorder, ipnURL := startEmulator(t, WithTransactionTimeout(time.Hour))
+
+	created, err := client.CreateTransaction(&coinpayments.CreateTransactionRequest{
+		Amount:     coinpayments.MustParseAmount("1"),
+		Currency1:  "LTC",
+		Currency2:  "LTC",
+		BuyerEmail: "buyer@example.com",
+		IPNURL:     ipnURL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := emulator.Pay(string(created.TxnId), "1/3"); err == nil {
+		t.Error("expected an error paying a fraction")
+	}
+	if err := emulator.Pay(string(created.TxnId), "0.4"); err != nil {
+		t.Fatal(err)
+	}
+
+	emulator.Advance(time.Hour)
+	assertStrings(t, "ipns", recorder.statuses(), "api waiting", "api cancelled")
+
+	recorder.mu.Lock()
+	cancelled, err := recorder.ipns[1].ToApiIPN()
+	recorder.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelled.ReceivedAmount.String() != "0.4" {
+		t.Errorf("cancelled ipn received_amount = %v, want 0.4", cancelled.ReceivedAmount)
+	}
+}
+
+func TestEmulatorWithdrawal(t *testing.T) {
+	emulator, client, recorder, ipnURL := startEmulator(t, WithBalance("BTC", "1"))
+
+	if _, err := client.CreateWithdrawal(&coinpayments.CreateWithdrawalRequest{
+		Amount:   coinpayments.MustParseAmount("2"),
+		Currency: "BTC",
+		Address:  "addr",
+	}); err == nil {
+		t.Error("expected an insufficient funds error")
+	}
+
+	created, err := client.CreateWithdrawal(&coinpayments.CreateWithdrawalRequest{
+		Amount:           coinpayments.MustParseAmount("0.25"),
+		Currency:         "BTC",
+		Address:          "addr",
+		IPNURL:           ipnURL,
+		SkipConfirmation: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Status != coinpayments.WithdrawalStatusPending {
+		t.Errorf("Status = %v, want pending", created.Status)
+	}
+	if got := emulator.Balance("BTC"); got != "0.75000000" {
+		t.Errorf("BTC balance = %v, want 0.75000000", got)
+	}
+
+	emulator.Advance(5 * time.Minute)
+
+	info, err := client.GetWithdrawalInfo(&coinpayments.GetWithdrawalInfoRequest{ID: string(created.ID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Status.IsComplete() || info.SendTXID == "" {
+		t.Errorf("unexpected withdrawal info %+v", info)
+	}
+	assertStrings(t, "ipns", recorder.statuses(), "withdrawal complete")
+}
+
+func TestEmulatorWithdrawalConfirmation(t *testing.T) {
+	emulator, client, _, _ := startEmulator(t, WithBalance("LTC", "5"))
+
+	created, err := client.CreateWithdrawal(&coinpayments.CreateWithdrawalRequest{
+		Amount:   coinpayments.MustParseAmount("1"),
+		Currency: "LTC",
+		Address:  "addr",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Status != coinpayments.WithdrawalStatusAwaitingEmail {
+		t.Fatalf("Status = %v, want awaiting email", created.Status)
+	}
+
+	emulator.Advance(time.Hour)
+	info, err := client.GetWithdrawalInfo(&coinpayments.GetWithdrawalInfoRequest{ID: string(created.ID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != coinpayments.WithdrawalStatusAwaitingEmail {
+		t.Errorf("unconfirmed withdrawal status = %v, want awaiting email", info.Status)
+	}
+
+	if err := emulator.ConfirmWithdrawal(string(created.ID)); err != nil {
+		t.Fatal(err)
+	}
+	emulator.Advance(5 * time.Minute)
+	if info, err = client.GetWithdrawalInfo(&coinpayments.GetWithdrawalInfoRequest{ID: string(created.ID)}); err != nil {
+		t.Fatal(err)
+	}
+	if !info.Status.IsComplete() {
+		t.Errorf("confirmed withdrawal status = %v, want complete", info.Status)
+	}
+}
+
+func TestEmulatorWithdrawalIPNOrder(t *testing.T) {
+	emulator, client, _, ipnURL := startEmulator(t, WithBalance("BTC", "1"))
+
+	var ids []string
+	for i := 0; i < 8; i++ {
+		created, err := client.CreateWithdrawal(&coinpayments.CreateWithdrawalRequest{
+			Amount:           coinpayments.MustParseAmount("0.01"),
+			Currency:         "BTC",
+			Address:          "addr",
+			IPNURL:           ipnURL,
+			SkipConfirmation: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, string(created.ID))
+	}
+
+	emulator.Advance(5 * time.Minute)
+	var delivered []string
+	for _, ipn := range emulator.Delivered() {
+		delivered = append(delivered, ipn.Values["id"])
+	}
+	assertStrings(t, "withdrawal ipn order", delivered, ids...)
+}
+
+func TestEmulatorTransfer(t *testing.T) {
+	emulator, client, _, _ := startEmulator(t, WithBalance("BTC", "1"))
+
+	confirmed, err := client.CreateTransfer(&coinpayments.CreateTransferRequest{
+		Amount:           coinpayments.MustParseAmount("0.1"),
+		Currency:         "BTC",
+		Merchant:         "other-merchant",
+		SkipConfirmation: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unconfirmed, err := client.CreateTransfer(&coinpayments.CreateTransferRequest{
+		Amount:   coinpayments.MustParseAmount("0.2"),
+		Currency: "BTC",
+		PBNTag:   "$other",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := emulator.Balance("BTC"); got != "0.70000000" {
+		t.Errorf("BTC balance = %v, want 0.70000000", got)
+	}
+
+	status := func(id string) coinpayments.WithdrawalStatus {
+		t.Helper()
+
+		info, err := client.GetWithdrawalInfo(&coinpayments.GetWithdrawalInfoRequest{ID: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Status
+	}
+
+	emulator.Advance(5 * time.Minute)
+	if got := status(string(confirmed.ID)); !got.IsComplete() {
+		t.Errorf("auto confirmed transfer status = %v, want complete", got)
+	}
+	if got := status(string(unconfirmed.ID)); got != coinpayments.WithdrawalStatusAwaitingEmail {
+		t.Errorf("unconfirmed transfer status = %v, want awaiting email", got)
+	}
+
+	if err := emulator.ConfirmWithdrawal(string(unconfirmed.ID)); err != nil {
+		t.Fatal(err)
+	}
+	emulator.Advance(5 * time.Minute)
+	if got := status(string(unconfirmed.ID)); !got.IsComplete() {
+		t.Errorf("confirmed transfer status = %v, want complete", got)
+	}
+}
+
+func TestEmulatorConversion(t *testing.T) {
+	emulator, client, _, _ := startEmulator(t, WithBalance("BTC", "1"))
+
+	created, err := client.Convert(&coinpayments.ConvertRequest{Amount: coinpayments.MustParseAmount("0.1"), From: "BTC", To: "LTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emulator.Advance(5 * time.Minute)
+	info, err := client.GetConversionInfo(&coinpayments.GetConversionInfoRequest{ID: string(created.ID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Status.IsComplete() {
+		t.Errorf("conversion status = %v, want complete", info.Status)
+	}
+	if got := emulator.Balance("LTC"); got != "25.00000000" {
+		t.Errorf("LTC balance = %v, want 25.00000000", got)
+	}
+	if got := emulator.Balance("BTC"); got != "0.90000000" {
+		t.Errorf("BTC balance = %v, want 0.90000000", got)
+	}
+}
+
+func TestEmulatorRatesAcceptance(t *testing.T) {
+	_, client, _, _ := startEmulator(t, WithoutAcceptance("ETH"))
+
+	catalog, err := client.Catalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var accepted []string
+	for _, coin := range catalog.Accepted() {
+		accepted = append(accepted, coin.Currency.String())
+	}
+	assertStrings(t, "accepted coins", accepted, "BTC", "LTC", "LTCT")
+	if coin, ok := catalog.Coin("USD"); !ok || !coin.IsFiat || coin.Accepted {
+		t.Errorf("USD = %+v, want an unaccepted fiat currency", coin)
+	}
+
+	rates, err := client.Rates(&coinpayments.RatesRequest{Acceptance: coinpayments.RatesOnlyAccepted, OmitDetails: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := (*rates)["ETH"]; ok {
+		t.Error("ETH should be left out of accepted=2 rates")
+	}
+	for _, code := range []coinpayments.Currency{"BTC", "USD", "EUR"} {
+		if _, ok := (*rates)[code]; !ok {
+			t.Errorf("%v should be in accepted=2 rates", code)
+		}
+	}
+}
+
+func TestEmulatorControlHandler(t *testing.T) {
+	emulator := NewEmulator(WithStartTime(time.Unix(1600000000, 0)))
+	control := httptest.NewServer(emulator.ControlHandler())
+	defer control.Close()
+
+	post := func(path string) int {
+		resp, err := control.Client().Post(control.URL+path, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post("/advance?duration=90m"); code != 200 {
+		t.Errorf("advance: got %v, want 200", code)
+	}
+	if got := emulator.Now().Unix(); got != 1600000000+90*60 {
+		t.Errorf("Now = %v, want 90 minutes after the start", got)
+	}
+	if code := post("/balance?coin=ltc&amount=2.5"); code != 200 {
+		t.Errorf("balance: got %v, want 200", code)
+	}
+	if got := emulator.Balance("LTC"); got != "2.50000000" {
+		t.Errorf("LTC balance = %v, want 2.50000000", got)
+	}
+	if code := post("/pay?txn_id=missing&amount=1"); code != 400 {
+		t.Errorf("pay for an unknown transaction: got %v, want 400", code)
+	}
+
+	resp, err := control.Client().Get(control.URL + "/advance?duration=1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 405 {
+		t.Errorf("GET advance: got %v, want 405", resp.StatusCode)
+	}
+}
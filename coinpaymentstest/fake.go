@@ -0,0 +1,305 @@
+package coinpaymentstest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/aidenesco/coinpayments"
+)
+
+var _ coinpayments.API = (*Fake)(nil)
+
+//Call is a call made to a Fake
+type Call struct {
+	Method  string
+	Request interface{}
+}
+
+//Fake is an in-memory coinpayments.API. Each method answers with its programmed func, or an error if none is set,
+//and every call is recorded
+type Fake struct {
+	BalancesFunc             func(request *coinpayments.BalancesRequest) (*coinpayments.BalancesResponse, error)
+	BuyPBNTagsFunc           func(request *coinpayments.BuyPBNTagsRequest) (*coinpayments.BuyPBNTagsResponse, error)
+	ClaimPBNCouponFunc       func(request *coinpayments.ClaimPBNCouponRequest) (*coinpayments.ClaimPBNCouponResponse, error)
+	ClaimPBNTagFunc          func(request *coinpayments.ClaimPBNTagRequest) (*coinpayments.ClaimPBNTagResponse, error)
+	ConvertFunc              func(request *coinpayments.ConvertRequest) (*coinpayments.ConvertResponse, error)
+	ConvertLimitsFunc        func(request *coinpayments.ConvertLimitsRequest) (*coinpayments.ConvertLimitsResponse, error)
+	CreateTransactionFunc    func(request *coinpayments.CreateTransactionRequest) (*coinpayments.CreateTransactionResponse, error)
+	CreateTransferFunc       func(request *coinpayments.CreateTransferRequest) (*coinpayments.CreateTransferResponse, error)
+	CreateWithdrawalFunc     func(request *coinpayments.CreateWithdrawalRequest) (*coinpayments.CreateWithdrawalResponse, error)
+	DeletePBNTagFunc         func(request *coinpayments.DeletePBNTagRequest) (*coinpayments.DeletePBNTagResponse, error)
+	GetBasicInfoFunc         func(request *coinpayments.GetBasicInfoRequest) (*coinpayments.GetBasicInfoResponse, error)
+	GetCallbackAddressFunc   func(request *coinpayments.GetCallbackAddressRequest) (*coinpayments.GetCallbackAddressResponse, error)
+	GetConversionInfoFunc    func(request *coinpayments.GetConversionInfoRequest) (*coinpayments.GetConversionInfoResponse, error)
+	GetDepositAddressFunc    func(request *coinpayments.GetDepositAddressRequest) (*coinpayments.GetDepositAddressResponse, error)
+	GetPBNInfoFunc           func(request *coinpayments.GetPBNInfoRequest) (*coinpayments.GetPBNInfoResponse, error)
+	GetPBNListFunc           func(request *coinpayments.GetPBNListRequest) (*coinpayments.GetPBNListResponse, error)
+	GetTxIdsFunc             func(request *coinpayments.GetTxIdsRequest) (*coinpayments.GetTxIdsResponse, error)
+	GetTxInfoFunc            func(request *coinpayments.GetTxInfoRequest) (*coinpayments.GetTxInfoResponse, error)
+	GetTxInfoMultiFunc       func(request *coinpayments.GetTxInfoMultiRequest) (*coinpayments.GetTxInfoMultiResponse, error)
+	GetWithdrawalHistoryFunc func(request *coinpayments.GetWithdrawalHistoryRequest) (*coinpayments.GetWithdrawalHistoryResponse, error)
+	GetWithdrawalInfoFunc    func(request *coinpayments.GetWithdrawalInfoRequest) (*coinpayments.GetWithdrawalInfoResponse, error)
+	RatesFunc                func(request *coinpayments.RatesRequest) (*coinpayments.RatesResponse, error)
+	RenewPBNTagFunc          func(request *coinpayments.RenewPBNTagRequest) (*coinpayments.RenewPBNTagResponse, error)
+	UpdatePBNTagFunc         func(request *coinpayments.UpdatePBNTagRequest) (*coinpayments.UpdatePBNTagResponse, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+//Calls returns every call made to the Fake
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Call(nil), f.calls...)
+}
+
+//CallsTo returns the calls made to the method
+func (f *Fake) CallsTo(method string) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var calls []Call
+	for _, call := range f.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+//Reset forgets every recorded call
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = nil
+}
+
+func (f *Fake) record(method string, request interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, Call{Method: method, Request: request})
+}
+
+func notProgrammed(method string) error {
+	return fmt.Errorf("coinpaymentstest: Fake.%v called but %vFunc is not set", method, method)
+}
+
+//Balances records the call and answers with BalancesFunc
+func (f *Fake) Balances(request *coinpayments.BalancesRequest) (*coinpayments.BalancesResponse, error) {
+	f.record("Balances", request)
+	if f.BalancesFunc == nil {
+		return nil, notProgrammed("Balances")
+	}
+	return f.BalancesFunc(request)
+}
+
+//BuyPBNTags records the call and answers with BuyPBNTagsFunc
+func (f *Fake) BuyPBNTags(request *coinpayments.BuyPBNTagsRequest) (*coinpayments.BuyPBNTagsResponse, error) {
+	f.record("BuyPBNTags", request)
+	if f.BuyPBNTagsFunc == nil {
+		return nil, notProgrammed("BuyPBNTags")
+	}
+	return f.BuyPBNTagsFunc(request)
+}
+
+//ClaimPBNCoupon records the call and answers with ClaimPBNCouponFunc
+func (f *Fake) ClaimPBNCoupon(request *coinpayments.ClaimPBNCouponRequest) (*coinpayments.ClaimPBNCouponResponse, error) {
+	f.record("ClaimPBNCoupon", request)
+	if f.ClaimPBNCouponFunc == nil {
+		return nil, notProgrammed("ClaimPBNCoupon")
+	}
+	return f.ClaimPBNCouponFunc(request)
+}
+
+//ClaimPBNTag records the call and answers with ClaimPBNTagFunc
+func (f *Fake) ClaimPBNTag(request *coinpayments.ClaimPBNTagRequest) (*coinpayments.ClaimPBNTagResponse, error) {
+	f.record("ClaimPBNTag", request)
+	if f.ClaimPBNTagFunc == nil {
+		return nil, notProgrammed("ClaimPBNTag")
+	}
+	return f.ClaimPBNTagFunc(request)
+}
+
+//Convert records the call and answers with ConvertFunc
+func (f *Fake) Convert(request *coinpayments.ConvertRequest) (*coinpayments.ConvertResponse, error) {
+	f.record("Convert", request)
+	if f.ConvertFunc == nil {
+		return nil, notProgrammed("Convert")
+	}
+	return f.ConvertFunc(request)
+}
+
+//ConvertLimits records the call and answers with ConvertLimitsFunc
+func (f *Fake) ConvertLimits(request *coinpayments.ConvertLimitsRequest) (*coinpayments.ConvertLimitsResponse, error) {
+	f.record("ConvertLimits", request)
+	if f.ConvertLimitsFunc == nil {
+		return nil, notProgrammed("ConvertLimits")
+	}
+	return f.ConvertLimitsFunc(request)
+}
+
+//CreateTransaction records the call and answers with CreateTransactionFunc
+func (f *Fake) CreateTransaction(request *coinpayments.CreateTransactionRequest) (*coinpayments.CreateTransactionResponse, error) {
+	f.record("CreateTransaction", request)
+	if f.CreateTransactionFunc == nil {
+		return nil, notProgrammed("CreateTransaction")
+	}
+	return f.CreateTransactionFunc(request)
+}
+
+//CreateTransfer records the call and answers with CreateTransferFunc
+func (f *Fake) CreateTransfer(request *coinpayments.CreateTransferRequest) (*coinpayments.CreateTransferResponse, error) {
+	f.record("CreateTransfer", request)
+	if f.CreateTransferFunc == nil {
+		return nil, notProgrammed("CreateTransfer")
+	}
+	return f.CreateTransferFunc(request)
+}
+
+//CreateWithdrawal records the call and answers with CreateWithdrawalFunc
+func (f *Fake) CreateWithdrawal(request *coinpayments.CreateWithdrawalRequest) (*coinpayments.CreateWithdrawalResponse, error) {
+	f.record("CreateWithdrawal", request)
+	if f.CreateWithdrawalFunc == nil {
+		return nil, notProgrammed("CreateWithdrawal")
+	}
+	return f.CreateWithdrawalFunc(request)
+}
+
+//DeletePBNTag records the call and answers with DeletePBNTagFunc
+func (f *Fake) DeletePBNTag(request *coinpayments.DeletePBNTagRequest) (*coinpayments.DeletePBNTagResponse, error) {
+	f.record("DeletePBNTag", request)
+	if f.DeletePBNTagFunc == nil {
+		return nil, notProgrammed("DeletePBNTag")
+	}
+	return f.DeletePBNTagFunc(request)
+}
+
+//GetBasicInfo records the call and answers with GetBasicInfoFunc
+func (f *Fake) GetBasicInfo(request *coinpayments.GetBasicInfoRequest) (*coinpayments.GetBasicInfoResponse, error) {
+	f.record("GetBasicInfo", request)
+	if f.GetBasicInfoFunc == nil {
+		return nil, notProgrammed("GetBasicInfo")
+	}
+	return f.GetBasicInfoFunc(request)
+}
+
+//GetCallbackAddress records the call and answers with GetCallbackAddressFunc
+func (f *Fake) GetCallbackAddress(request *coinpayments.GetCallbackAddressRequest) (*coinpayments.GetCallbackAddressResponse, error) {
+	f.record("GetCallbackAddress", request)
+	if f.GetCallbackAddressFunc == nil {
+		return nil, notProgrammed("GetCallbackAddress")
+	}
+	return f.GetCallbackAddressFunc(request)
+}
+
+//GetConversionInfo records the call and answers with GetConversionInfoFunc
+func (f *Fake) GetConversionInfo(request *coinpayments.GetConversionInfoRequest) (*coinpayments.GetConversionInfoResponse, error) {
+	f.record("GetConversionInfo", request)
+	if f.GetConversionInfoFunc == nil {
+		return nil, notProgrammed("GetConversionInfo")
+	}
+	return f.GetConversionInfoFunc(request)
+}
+
+//GetDepositAddress records the call and answers with GetDepositAddressFunc
+func (f *Fake) GetDepositAddress(request *coinpayments.GetDepositAddressRequest) (*coinpayments.GetDepositAddressResponse, error) {
+	f.record("GetDepositAddress", request)
+	if f.GetDepositAddressFunc == nil {
+		return nil, notProgrammed("GetDepositAddress")
+	}
+	return f.GetDepositAddressFunc(request)
+}
+
+//GetPBNInfo records the call and answers with GetPBNInfoFunc
+func (f *Fake) GetPBNInfo(request *coinpayments.GetPBNInfoRequest) (*coinpayments.GetPBNInfoResponse, error) {
+	f.record("GetPBNInfo", request)
+	if f.GetPBNInfoFunc == nil {
+		return nil, notProgrammed("GetPBNInfo")
+	}
+	return f.GetPBNInfoFunc(request)
+}
+
+//GetPBNList records the call and answers with GetPBNListFunc
+func (f *Fake) GetPBNList(request *coinpayments.GetPBNListRequest) (*coinpayments.GetPBNListResponse, error) {
+	f.record("GetPBNList", request)
+	if f.GetPBNListFunc == nil {
+		return nil, notProgrammed("GetPBNList")
+	}
+	return f.GetPBNListFunc(request)
+}
+
+//GetTxIds records the call and answers with GetTxIdsFunc
+func (f *Fake) GetTxIds(request *coinpayments.GetTxIdsRequest) (*coinpayments.GetTxIdsResponse, error) {
+	f.record("GetTxIds", request)
+	if f.GetTxIdsFunc == nil {
+		return nil, notProgrammed("GetTxIds")
+	}
+	return f.GetTxIdsFunc(request)
+}
+
+//GetTxInfo records the call and answers with GetTxInfoFunc
+func (f *Fake) GetTxInfo(request *coinpayments.GetTxInfoRequest) (*coinpayments.GetTxInfoResponse, error) {
+	f.record("GetTxInfo", request)
+	if f.GetTxInfoFunc == nil {
+		return nil, notProgrammed("GetTxInfo")
+	}
+	return f.GetTxInfoFunc(request)
+}
+
+//GetTxInfoMulti records the call and answers with GetTxInfoMultiFunc
+func (f *Fake) GetTxInfoMulti(request *coinpayments.GetTxInfoMultiRequest) (*coinpayments.GetTxInfoMultiResponse, error) {
+	f.record("GetTxInfoMulti", request)
+	if f.GetTxInfoMultiFunc == nil {
+		return nil, notProgrammed("GetTxInfoMulti")
+	}
+	return f.GetTxInfoMultiFunc(request)
+}
+
+//GetWithdrawalHistory records the call and answers with GetWithdrawalHistoryFunc
+func (f *Fake) GetWithdrawalHistory(request *coinpayments.GetWithdrawalHistoryRequest) (*coinpayments.GetWithdrawalHistoryResponse, error) {
+	f.record("GetWithdrawalHistory", request)
+	if f.GetWithdrawalHistoryFunc == nil {
+		return nil, notProgrammed("GetWithdrawalHistory")
+	}
+	return f.GetWithdrawalHistoryFunc(request)
+}
+
+//GetWithdrawalInfo records the call and answers with GetWithdrawalInfoFunc
+func (f *Fake) GetWithdrawalInfo(request *coinpayments.GetWithdrawalInfoRequest) (*coinpayments.GetWithdrawalInfoResponse, error) {
+	f.record("GetWithdrawalInfo", request)
+	if f.GetWithdrawalInfoFunc == nil {
+		return nil, notProgrammed("GetWithdrawalInfo")
+	}
+	return f.GetWithdrawalInfoFunc(request)
+}
+
+//Rates records the call and answers with RatesFunc
+func (f *Fake) Rates(request *coinpayments.RatesRequest) (*coinpayments.RatesResponse, error) {
+	f.record("Rates", request)
+	if f.RatesFunc == nil {
+		return nil, notProgrammed("Rates")
+	}
+	return f.RatesFunc(request)
+}
+
+//RenewPBNTag records the call and answers with RenewPBNTagFunc
+func (f *Fake) RenewPBNTag(request *coinpayments.RenewPBNTagRequest) (*coinpayments.RenewPBNTagResponse, error) {
+	f.record("RenewPBNTag", request)
+	if f.RenewPBNTagFunc == nil {
+		return nil, notProgrammed("RenewPBNTag")
+	}
+	return f.RenewPBNTagFunc(request)
+}
+
+//UpdatePBNTag records the call and answers with UpdatePBNTagFunc
+func (f *Fake) UpdatePBNTag(request *coinpayments.UpdatePBNTagRequest) (*coinpayments.UpdatePBNTagResponse, error) {
+	f.record("UpdatePBNTag", request)
+	if f.UpdatePBNTagFunc == nil {
+		return nil, notProgrammed("UpdatePBNTag")
+	}
+	return f.UpdatePBNTagFunc(request)
+}
@@ -0,0 +1,73 @@
+package coinpaymentstest
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aidenesco/coinpayments"
+)
+
+func TestFakeNotProgrammed(t *testing.T) {
+	fake := &Fake{}
+	api := reflect.TypeOf((*coinpayments.API)(nil)).Elem()
+
+	for i := 0; i < api.NumMethod(); i++ {
+		method := api.Method(i)
+		request := reflect.New(method.Type.In(0).Elem())
+		out := reflect.ValueOf(fake).MethodByName(method.Name).Call([]reflect.Value{request})
+
+		if !out[0].IsNil() {
+			t.Errorf("%v: got a response from an unprogrammed method", method.Name)
+		}
+		err, _ := out[1].Interface().(error)
+		if err == nil || !strings.Contains(err.Error(), method.Name+"Func is not set") {
+			t.Errorf("%v: got error %v, want a not programmed error", method.Name, err)
+		}
+	}
+
+	if got := len(fake.Calls()); got != api.NumMethod() {
+		t.Errorf("recorded %v calls, want one for each of the %v methods", got, api.NumMethod())
+	}
+}
+
+func TestFakeProgrammed(t *testing.T) {
+	fake := &Fake{
+		GetTxInfoFunc: func(request *coinpayments.GetTxInfoRequest) (*coinpayments.GetTxInfoResponse, error) {
+			if request.TXID == "missing" {
+				return nil, errors.New("Invalid transaction ID")
+			}
+			return &coinpayments.GetTxInfoResponse{Status: coinpayments.PaymentStatusComplete}, nil
+		},
+	}
+	var api coinpayments.API = fake
+
+	info, err := api.GetTxInfo(&coinpayments.GetTxInfoRequest{TXID: "CP1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Status.IsComplete() {
+		t.Errorf("Status = %v, want complete", info.Status)
+	}
+	if _, err := api.GetTxInfo(&coinpayments.GetTxInfoRequest{TXID: "missing"}); err == nil {
+		t.Error("expected the programmed error")
+	}
+	api.Balances(&coinpayments.BalancesRequest{})
+
+	calls := fake.CallsTo("GetTxInfo")
+	if len(calls) != 2 {
+		t.Fatalf("got %v GetTxInfo calls, want 2", len(calls))
+	}
+	if request, ok := calls[1].Request.(*coinpayments.GetTxInfoRequest); !ok || request.TXID != "missing" {
+		t.Errorf("second call request = %+v, want the missing txid", calls[1].Request)
+	}
+	if got := len(fake.Calls()); got != 3 {
+		t.Errorf("recorded %v calls, want 3", got)
+	}
+
+	fake.Reset()
+	if len(fake.Calls()) != 0 || len(fake.CallsTo("GetTxInfo")) != 0 {
+		t.Error("Reset should forget every call")
+	}
+}
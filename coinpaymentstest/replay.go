@@ -0,0 +1,193 @@
+package coinpaymentstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+)
+
+const redacted = "REDACTED"
+
+//Interaction is a recorded api call and the response to it
+type Interaction struct {
+	Command    string     `json:"command"`
+	Values     url.Values `json:"values"`
+	StatusCode int        `json:"status_code"`
+	Body       string     `json:"body"`
+}
+
+type fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+//Recorder is an http.RoundTripper that records api calls to a fixture file for a Replayer to serve later.
+//The public key is redacted and the HMAC header is not recorded
+type Recorder struct {
+	path      string
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+//NewRecorder returns a new Recorder that sends api calls with transport, or http.DefaultTransport if it is nil,
+//and saves them to path
+func NewRecorder(path string, transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{
+		path:      path,
+		transport: transport,
+	}
+}
+
+//RoundTrip sends the api call and records it along with its response
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	values, err := readForm(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("coinpaymentstest: error reading response to record - %v", err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Command:    values.Get("cmd"),
+		Values:     scrub(values),
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+	})
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+//Interactions returns the api calls recorded so far
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Interaction(nil), r.interactions...)
+}
+
+//Save writes the recorded api calls to the fixture file
+func (r *Recorder) Save() error {
+	data, err := json.MarshalIndent(fixture{Interactions: r.Interactions()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("coinpaymentstest: error marshaling fixture - %v", err)
+	}
+	if err := ioutil.WriteFile(r.path, data, 0644); err != nil {
+		return fmt.Errorf("coinpaymentstest: error writing fixture - %v", err)
+	}
+	return nil
+}
+
+//Replayer is an http.RoundTripper that answers api calls from a fixture file written by a Recorder. Calls are
+//matched by command and parameters, each recorded interaction is used once and in order, and a call with no
+//matching interaction fails with an error
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+//NewReplayer returns a new Replayer serving the fixture at path
+func NewReplayer(path string) (*Replayer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("coinpaymentstest: error reading fixture - %v", err)
+	}
+
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("coinpaymentstest: error unmarshaling fixture - %v", err)
+	}
+
+	return &Replayer{
+		interactions: f.Interactions,
+		used:         make([]bool, len(f.Interactions)),
+	}, nil
+}
+
+//RoundTrip answers the api call with the first unused interaction recorded for the same command and parameters
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	values, err := readForm(req)
+	if err != nil {
+		return nil, err
+	}
+	values = scrub(values)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.interactions {
+		if r.used[i] || !reflect.DeepEqual(interaction.Values, values) {
+			continue
+		}
+		r.used[i] = true
+
+		return response(req, interaction.StatusCode, []byte(interaction.Body)), nil
+	}
+
+	return nil, fmt.Errorf("coinpaymentstest: no unused recorded interaction for cmd %q with values %v", values.Get("cmd"), values.Encode())
+}
+
+//Unused returns the recorded interactions that have not been replayed
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []Interaction
+	for i, interaction := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+func readForm(req *http.Request) (url.Values, error) {
+	if req.Body == nil {
+		return url.Values{}, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("coinpaymentstest: error reading request body - %v", err)
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("coinpaymentstest: error parsing request body - %v", err)
+	}
+	return values, nil
+}
+
+func scrub(values url.Values) url.Values {
+	scrubbed := url.Values{}
+	for k, v := range values {
+		scrubbed[k] = append([]string(nil), v...)
+	}
+	if scrubbed.Get("key") != "" {
+		scrubbed.Set("key", redacted)
+	}
+	return scrubbed
+}
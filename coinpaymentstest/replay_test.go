@@ -0,0 +1,87 @@
+package coinpaymentstest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aidenesco/coinpayments"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "basic_info.json")
+
+	server := NewServer()
+	defer server.Close()
+	server.SetResult("get_basic_info", map[string]interface{}{"username": "first"})
+
+	recorder := NewRecorder(path, nil)
+	client := server.Client(coinpayments.WithHTTPClient(&http.Client{Transport: recorder}))
+	if _, err := client.GetBasicInfo(&coinpayments.GetBasicInfoRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	server.SetResult("get_basic_info", map[string]interface{}{"username": "second"})
+	if _, err := client.GetBasicInfo(&coinpayments.GetBasicInfoRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	server.SetResult("get_tx_info", map[string]interface{}{"status": 100})
+	if _, err := client.GetTxInfo(&coinpayments.GetTxInfoRequest{TXID: "CP1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), PublicKey) || !strings.Contains(string(data), redacted) {
+		t.Error("the public key should be redacted from the fixture")
+	}
+	if len(recorder.Interactions()) != 3 {
+		t.Errorf("recorded %v interactions, want 3", len(recorder.Interactions()))
+	}
+
+	replayer, err := NewReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := coinpayments.NewClient("other-public", "other-private",
+		coinpayments.WithAPIURL("http://replay.invalid"), coinpayments.WithHTTPClient(&http.Client{Transport: replayer}))
+
+	for _, want := range []string{"first", "second"} {
+		info, err := replayed.GetBasicInfo(&coinpayments.GetBasicInfoRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(info.Username) != want {
+			t.Errorf("Username = %q, want %q", info.Username, want)
+		}
+	}
+	if _, err := replayed.GetBasicInfo(&coinpayments.GetBasicInfoRequest{}); err == nil {
+		t.Error("expected an error once the recorded interactions are used up")
+	}
+	if _, err := replayed.GetTxInfo(&coinpayments.GetTxInfoRequest{TXID: "CP2"}); err == nil {
+		t.Error("expected an error for a call with other parameters")
+	}
+
+	unused := replayer.Unused()
+	if len(unused) != 1 || unused[0].Command != "get_tx_info" {
+		t.Errorf("Unused = %+v, want the get_tx_info interaction", unused)
+	}
+}
+
+func TestNewReplayerMissingFixture(t *testing.T) {
+	if _, err := NewReplayer(filepath.Join(os.TempDir(), "coinpaymentstest-missing-fixture.json")); err == nil {
+		t.Error("expected an error for a missing fixture")
+	}
+}
@@ -0,0 +1,207 @@
+//Package coinpaymentstest provides an in-process coinpayments api for testing code that uses a coinpayments.Client
+package coinpaymentstest
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	"github.com/aidenesco/coinpayments"
+)
+
+const (
+	//PublicKey is the public key the Server accepts by default
+	PublicKey = "coinpaymentstest-public"
+	//PrivateKey is the private key the Server accepts by default
+	PrivateKey = "coinpaymentstest-private"
+)
+
+//Request is an api call received by a Server
+type Request struct {
+	Command string
+	Values  url.Values
+	Header  http.Header
+}
+
+//Server is an httptest server that answers api calls with registered results after verifying their HMAC
+type Server struct {
+	*httptest.Server
+	PublicKey  string
+	PrivateKey string
+
+	mu       sync.Mutex
+	results  map[string]interface{}
+	errors   map[string]string
+	requests []Request
+}
+
+//NewServer starts and returns a new Server that accepts PublicKey and PrivateKey
+func NewServer() *Server {
+	return NewServerWithKeys(PublicKey, PrivateKey)
+}
+
+//NewServerWithKeys starts and returns a new Server that accepts the provided keys
+func NewServerWithKeys(publicKey, privateKey string) *Server {
+	s := &Server{
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+		results:    make(map[string]interface{}),
+		errors:     make(map[string]string),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+//Client returns a Client that calls the Server with its keys
+func (s *Server) Client(options ...coinpayments.ClientOption) *coinpayments.Client {
+	options = append([]coinpayments.ClientOption{
+		coinpayments.WithHTTPClient(s.Server.Client()),
+		coinpayments.WithAPIURL(s.URL),
+	}, options...)
+	return coinpayments.NewClient(s.PublicKey, s.PrivateKey, options...)
+}
+
+//SetResult makes the Server answer the command with the result, which is marshaled to json
+func (s *Server) SetResult(command string, result interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.errors, command)
+	s.results[command] = result
+}
+
+//SetError makes the Server answer the command with the api error message
+func (s *Server) SetError(command string, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.results, command)
+	s.errors[command] = message
+}
+
+//Requests returns every api call the Server has received
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+//RequestsFor returns the api calls the Server has received for the command
+func (s *Server) RequestsFor(command string) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var requests []Request
+	for _, r := range s.requests {
+		if r.Command == command {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+//Reset forgets every registered result, error and received api call
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.results = make(map[string]interface{})
+	s.errors = make(map[string]string)
+	s.requests = nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	values, message, ok := readCall(w, r, s.PublicKey, s.PrivateKey)
+	if values == nil {
+		return
+	}
+
+	command := values.Get("cmd")
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Command: command,
+		Values:  values,
+		Header:  r.Header.Clone(),
+	})
+	result, hasResult := s.results[command]
+	errMessage, hasError := s.errors[command]
+	s.mu.Unlock()
+
+	switch {
+	case !ok:
+		writeError(w, message)
+	case hasError:
+		writeError(w, errMessage)
+	case hasResult:
+		writeResult(w, result)
+	default:
+		writeError(w, fmt.Sprintf("No result registered for command %q", command))
+	}
+}
+
+//readCall reads the form values of an api call and checks its HMAC and key the way coinpayments does. The values are
+//nil if a response has already been written, and ok is false with the api error message if the checks failed
+func readCall(w http.ResponseWriter, r *http.Request, publicKey, privateKey string) (url.Values, string, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return nil, "", false
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, "", false
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		writeError(w, "Invalid request body")
+		return nil, "", false
+	}
+
+	switch {
+	case r.Header.Get("HMAC") == "":
+		return values, "No HMAC signature sent.", false
+	case !hmac.Equal([]byte(r.Header.Get("HMAC")), []byte(Sign(body, privateKey))):
+		return values, "HMAC signature does not match", false
+	case values.Get("key") != publicKey:
+		return values, "Invalid public key!", false
+	case values.Get("version") != "1":
+		return values, "Invalid API version - no match found for the provided key", false
+	case values.Get("format") != "" && values.Get("format") != "json":
+		return values, "Invalid format!", false
+	}
+	return values, "", true
+}
+
+//Sign returns the HMAC coinpayments expects in the 'HMAC' header of an api call, which is made the same way as that of
+//an IPN
+func Sign(body []byte, privateKey string) string {
+	return coinpayments.SignIPN(body, privateKey)
+}
+
+func writeError(w http.ResponseWriter, message string) {
+	writeJSON(w, map[string]interface{}{"error": message})
+}
+
+func writeResult(w http.ResponseWriter, result interface{}) {
+	writeJSON(w, map[string]interface{}{"error": "ok", "result": result})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
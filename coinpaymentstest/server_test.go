@@ -0,0 +1,90 @@
+package coinpaymentstest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aidenesco/coinpayments"
+)
+
+func TestServerResult(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.SetResult("get_basic_info", map[string]interface{}{"username": "tester", "merchant_id": "M1"})
+	info, err := server.Client().GetBasicInfo(&coinpayments.GetBasicInfoRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Username != "tester" || info.MerchantID != "M1" {
+		t.Errorf("unexpected info %+v", info)
+	}
+
+	requests := server.RequestsFor("get_basic_info")
+	if len(requests) != 1 {
+		t.Fatalf("got %v get_basic_info requests, want 1", len(requests))
+	}
+	if values := requests[0].Values; values.Get("key") != PublicKey || values.Get("version") != "1" || values.Get("format") != "json" {
+		t.Errorf("unexpected request values %v", values)
+	}
+}
+
+func TestServerError(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	server.SetError("get_basic_info", "Invalid permissions")
+	if _, err := client.GetBasicInfo(&coinpayments.GetBasicInfoRequest{}); err == nil || !strings.Contains(err.Error(), "Invalid permissions") {
+		t.Errorf("got error %v, want the registered error", err)
+	}
+
+	if _, err := client.GetPBNList(&coinpayments.GetPBNListRequest{}); err == nil || !strings.Contains(err.Error(), "No result registered") {
+		t.Errorf("got error %v, want an unregistered command error", err)
+	}
+
+	server.Reset()
+	if len(server.Requests()) != 0 {
+		t.Error("Reset should forget received requests")
+	}
+	if _, err := client.GetBasicInfo(&coinpayments.GetBasicInfoRequest{}); err == nil || !strings.Contains(err.Error(), "No result registered") {
+		t.Errorf("got error %v, want Reset to forget the registered error", err)
+	}
+}
+
+func TestServerChecksKeys(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.SetResult("get_basic_info", map[string]interface{}{"username": "tester"})
+
+	tests := []struct {
+		name      string
+		client    *coinpayments.Client
+		wantError string
+	}{
+		{
+			name:      "wrong private key",
+			client:    coinpayments.NewClient(PublicKey, "wrong", coinpayments.WithAPIURL(server.URL)),
+			wantError: "HMAC signature does not match",
+		},
+		{
+			name:      "wrong public key",
+			client:    coinpayments.NewClient("wrong", PrivateKey, coinpayments.WithAPIURL(server.URL)),
+			wantError: "Invalid public key!",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := test.client.GetBasicInfo(&coinpayments.GetBasicInfoRequest{})
+		if err == nil || !strings.Contains(err.Error(), test.wantError) {
+			t.Errorf("%v: got error %v, want %q", test.name, err, test.wantError)
+		}
+	}
+
+	custom := NewServerWithKeys("pub", "priv")
+	defer custom.Close()
+	custom.SetResult("get_basic_info", map[string]interface{}{"username": "tester"})
+	if _, err := custom.Client().GetBasicInfo(&coinpayments.GetBasicInfoRequest{}); err != nil {
+		t.Errorf("custom keys rejected: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package coinpayments
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Za-z0-9]+(\.[A-Za-z0-9]+)?$`)
+
+//testCoin is the litecoin testnet coin coinpayments offers for testing payments
+const testCoin = "LTCT"
+
+//Currency is a coinpayments currency code, either a bare ticker such as BTC or USD or a ticker followed by the
+//network it is sent on, such as USDT.ERC20 or BNB.BSC. Codes compare case-insensitively
+type Currency string
+
+//ParseCurrency parses a currency code, returning it in upper case
+func ParseCurrency(s string) (Currency, error) {
+	s = strings.TrimSpace(s)
+	if !currencyPattern.MatchString(s) {
+		return "", fmt.Errorf("coinpayments: invalid currency %q", s)
+	}
+	return Currency(strings.ToUpper(s)), nil
+}
+
+//Ticker returns the currency without its network, so USDT.ERC20 returns USDT
+func (c Currency) Ticker() string {
+	ticker := strings.TrimSpace(string(c))
+	if i := strings.Index(ticker, "."); i >= 0 {
+		ticker = ticker[:i]
+	}
+	return strings.ToUpper(ticker)
+}
+
+//Network returns the network the currency is sent on, so USDT.ERC20 returns ERC20, or an empty string for a bare
+//ticker
+func (c Currency) Network() string {
+	s := strings.TrimSpace(string(c))
+	if i := strings.Index(s, "."); i >= 0 {
+		return strings.ToUpper(s[i+1:])
+	}
+	return ""
+}
+
+//IsTestCoin reports whether the currency is the LTCT test coin
+func (c Currency) IsTestCoin() bool {
+	return c.Equal(testCoin)
+}
+
+//IsFiat reports whether the rates list the currency as fiat
+func (c Currency) IsFiat(rates RatesResponse) bool {
+	for code, rate := range rates {
+		if c.Equal(code) {
+			return bool(rate.IsFiat)
+		}
+	}
+	return false
+}
+
+//Equal reports whether two currency codes are the same, ignoring case
+func (c Currency) Equal(other Currency) bool {
+	return strings.EqualFold(strings.TrimSpace(string(c)), strings.TrimSpace(string(other)))
+}
+
+//String returns the currency code
+func (c Currency) String() string {
+	return string(c)
+}
+
+//UnmarshalJSON decodes a json string, or a number the api sent in place of one
+func (c *Currency) UnmarshalJSON(data []byte) error {
+	s, err := flexScalar(data)
+	if err != nil {
+		return err
+	}
+	*c = Currency(s)
+	return nil
+}
@@ -0,0 +1,135 @@
+package coinpayments
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Currency
+		wantErr bool
+	}{
+		{in: "BTC", want: "BTC"},
+		{in: "btc", want: "BTC"},
+		{in: " ltct ", want: "LTCT"},
+		{in: "usdt.erc20", want: "USDT.ERC20"},
+		{in: "1INCH", want: "1INCH"},
+		{in: "", wantErr: true},
+		{in: "B C", wantErr: true},
+		{in: "USDT.", wantErr: true},
+		{in: ".ERC20", wantErr: true},
+		{in: "USDT.ERC20.X", wantErr: true},
+		{in: "BTC$", wantErr: true},
+	}
+
+	for _, test := range tests {
+		c, err := ParseCurrency(test.in)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParseCurrency(%q) = %v, want an error", test.in, c)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseCurrency(%q) returned error %v", test.in, err)
+			continue
+		}
+		if c != test.want {
+			t.Errorf("ParseCurrency(%q) = %v, want %v", test.in, c, test.want)
+		}
+	}
+}
+
+func TestCurrencyParts(t *testing.T) {
+	tests := []struct {
+		in      Currency
+		ticker  string
+		network string
+		test    bool
+	}{
+		{in: "BTC", ticker: "BTC"},
+		{in: "usdt.erc20", ticker: "USDT", network: "ERC20"},
+		{in: " BNB.BSC ", ticker: "BNB", network: "BSC"},
+		{in: "LTCT", ticker: "LTCT", test: true},
+		{in: "ltct", ticker: "LTCT", test: true},
+		{in: "LTC", ticker: "LTC"},
+	}
+
+	for _, test := range tests {
+		if got := test.in.Ticker(); got != test.ticker {
+			t.Errorf("%q.Ticker() = %q, want %q", test.in, got, test.ticker)
+		}
+		if got := test.in.Network(); got != test.network {
+			t.Errorf("%q.Network() = %q, want %q", test.in, got, test.network)
+		}
+		if got := test.in.IsTestCoin(); got != test.test {
+			t.Errorf("%q.IsTestCoin() = %v, want %v", test.in, got, test.test)
+		}
+	}
+}
+
+func TestCurrencyEqual(t *testing.T) {
+	tests := []struct {
+		a, b Currency
+		want bool
+	}{
+		{a: "BTC", b: "btc", want: true},
+		{a: " BTC", b: "BTC ", want: true},
+		{a: "USDT.ERC20", b: "usdt.erc20", want: true},
+		{a: "USDT", b: "USDT.ERC20"},
+		{a: "LTC", b: "LTCT"},
+	}
+
+	for _, test := range tests {
+		if got := test.a.Equal(test.b); got != test.want {
+			t.Errorf("%q.Equal(%q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestCurrencyIsFiat(t *testing.T) {
+	var rates RatesResponse
+	if err := json.Unmarshal([]byte(`{"BTC":{"is_fiat":0},"USD":{"is_fiat":1}}`), &rates); err != nil {
+		t.Fatal(err)
+	}
+
+	for c, want := range map[Currency]bool{"USD": true, "usd": true, "BTC": false, "EUR": false} {
+		if got := c.IsFiat(rates); got != want {
+			t.Errorf("%q.IsFiat() = %v, want %v", c, got, want)
+		}
+	}
+}
+
+func TestCurrencyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Currency
+		wantErr bool
+	}{
+		{in: `"BTC"`, want: "BTC"},
+		{in: `"usdt.erc20"`, want: "usdt.erc20"},
+		{in: `1337`, want: "1337"},
+		{in: `null`, want: ""},
+		{in: `["BTC"]`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		var c Currency
+		err := json.Unmarshal([]byte(test.in), &c)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("decoding %v = %v, want an error", test.in, c)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("decoding %v returned error %v", test.in, err)
+			continue
+		}
+		if c.String() != string(test.want) {
+			t.Errorf("decoding %v = %q, want %q", test.in, c, test.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package coinpayments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//FlexInt is an integer the api may send as a json number or a string
+type FlexInt int64
+
+//UnmarshalJSON decodes a json number or string, treating null and an empty string as 0
+func (i *FlexInt) UnmarshalJSON(data []byte) error {
+	s, err := flexScalar(data)
+	if err != nil {
+		return err
+	}
+	if s == "" {
+		*i = 0
+		return nil
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		f, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil || f != float64(int64(f)) {
+			return fmt.Errorf("coinpayments: cannot decode %s as an integer", data)
+		}
+		n = int64(f)
+	}
+	*i = FlexInt(n)
+	return nil
+}
+
+//FlexString is a string the api may send as a json string, number or boolean
+type FlexString string
+
+//UnmarshalJSON decodes a json string, number or boolean, treating null as an empty string
+func (s *FlexString) UnmarshalJSON(data []byte) error {
+	value, err := flexScalar(data)
+	if err != nil {
+		return err
+	}
+	*s = FlexString(value)
+	return nil
+}
+
+//String returns the string
+func (s FlexString) String() string {
+	return string(s)
+}
+
+//FlexBool is a boolean the api may send as a json boolean, a 0 or 1 number, or a string of either
+type FlexBool bool
+
+//UnmarshalJSON decodes a json boolean, number or string, treating null, an empty string and 0 as false
+func (b *FlexBool) UnmarshalJSON(data []byte) error {
+	s, err := flexScalar(data)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(s) {
+	case "", "0", "false":
+		*b = false
+	case "1", "true":
+		*b = true
+	default:
+		return fmt.Errorf("coinpayments: cannot decode %s as a boolean", data)
+	}
+	return nil
+}
+
+//flexScalar returns the text of a json string, number, boolean or null, which is returned as an empty string
+func flexScalar(data []byte) (string, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return "", nil
+	}
+
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(s), nil
+	}
+
+	if data[0] == '{' || data[0] == '[' {
+		return "", fmt.Errorf("coinpayments: cannot decode %s as a scalar", data)
+	}
+	return string(data), nil
+}
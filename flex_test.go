@@ -0,0 +1,135 @@
+package coinpayments
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFlexInt(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    FlexInt
+		wantErr bool
+	}{
+		{in: `12`, want: 12},
+		{in: `"12"`, want: 12},
+		{in: `" 12 "`, want: 12},
+		{in: `-3`, want: -3},
+		{in: `3.0`, want: 3},
+		{in: `"3.0"`, want: 3},
+		{in: `null`, want: 0},
+		{in: `""`, want: 0},
+		{in: `3.5`, wantErr: true},
+		{in: `"abc"`, wantErr: true},
+		{in: `true`, wantErr: true},
+		{in: `[1]`, wantErr: true},
+		{in: `{}`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		i := FlexInt(99)
+		err := json.Unmarshal([]byte(test.in), &i)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("decoding %v = %v, want an error", test.in, i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("decoding %v returned error %v", test.in, err)
+			continue
+		}
+		if i != test.want {
+			t.Errorf("decoding %v = %v, want %v", test.in, i, test.want)
+		}
+	}
+}
+
+func TestFlexString(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    FlexString
+		wantErr bool
+	}{
+		{in: `"abc"`, want: "abc"},
+		{in: `" abc "`, want: "abc"},
+		{in: `12345`, want: "12345"},
+		{in: `0.5`, want: "0.5"},
+		{in: `true`, want: "true"},
+		{in: `null`, want: ""},
+		{in: `["abc"]`, wantErr: true},
+		{in: `{"a":"b"}`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		s := FlexString("previous")
+		err := json.Unmarshal([]byte(test.in), &s)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("decoding %v = %q, want an error", test.in, s)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("decoding %v returned error %v", test.in, err)
+			continue
+		}
+		if s.String() != string(test.want) {
+			t.Errorf("decoding %v = %q, want %q", test.in, s, test.want)
+		}
+	}
+}
+
+func TestFlexBool(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    FlexBool
+		wantErr bool
+	}{
+		{in: `true`, want: true},
+		{in: `false`, want: false},
+		{in: `1`, want: true},
+		{in: `0`, want: false},
+		{in: `"1"`, want: true},
+		{in: `"0"`, want: false},
+		{in: `"True"`, want: true},
+		{in: `"FALSE"`, want: false},
+		{in: `""`, want: false},
+		{in: `null`, want: false},
+		{in: `2`, wantErr: true},
+		{in: `"yes"`, wantErr: true},
+		{in: `[true]`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		b := FlexBool(!test.want)
+		err := json.Unmarshal([]byte(test.in), &b)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("decoding %v = %v, want an error", test.in, b)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("decoding %v returned error %v", test.in, err)
+			continue
+		}
+		if b != test.want {
+			t.Errorf("decoding %v = %v, want %v", test.in, b, test.want)
+		}
+	}
+}
+
+func TestFlexFields(t *testing.T) {
+	var info struct {
+		Confirms FlexInt    `json:"confirms"`
+		TxID     FlexString `json:"txid"`
+		Accepted FlexBool   `json:"accepted"`
+	}
+	if err := json.Unmarshal([]byte(`{"confirms":"3","txid":12345,"accepted":"1"}`), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Confirms != 3 || info.TxID != "12345" || !info.Accepted {
+		t.Errorf("unexpected decode %+v", info)
+	}
+}
@@ -0,0 +1,142 @@
+package coinpayments
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	amountType = reflect.TypeOf(Amount{})
+	timeType   = reflect.TypeOf(time.Time{})
+)
+
+//encodeForm encodes a request struct as api form values using the `form` tag on each field, which holds the
+//parameter name followed by options. Fields without a tag are left out, as are zero values of fields with the
+//omitempty option. Bools encode as 1 or 0, Amounts as exact decimals, times as Unix seconds and slices of structs
+//as indexed parameters such as wd[0][amount]. When several fields share a name the first one encoded wins, which
+//lets a deprecated field act as a fallback for the field replacing it
+func encodeForm(request interface{}) (url.Values, error) {
+	v := reflect.ValueOf(request)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, fmt.Errorf("coinpayments: cannot encode nil %v", v.Type())
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("coinpayments: cannot encode %v as a form", v.Type())
+	}
+
+	values := url.Values{}
+	if err := encodeFormStruct(values, "", v); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func encodeFormStruct(values url.Values, prefix string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("form")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		name, options := parseFormTag(tag)
+		if prefix != "" {
+			name = prefix + "[" + name + "]"
+		}
+
+		fv := v.Field(i)
+		if options.omitempty && isEmptyFormValue(fv) {
+			continue
+		}
+
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Struct {
+			for j := 0; j < fv.Len(); j++ {
+				if err := encodeFormStruct(values, fmt.Sprintf("%v[%d]", name, j), fv.Index(j)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if _, ok := values[name]; ok {
+			continue
+		}
+
+		value, err := formatFormValue(fv)
+		if err != nil {
+			return fmt.Errorf("coinpayments: cannot encode %v.%v - %v", t, field.Name, err)
+		}
+		values.Set(name, value)
+	}
+	return nil
+}
+
+type formOptions struct {
+	omitempty bool
+}
+
+func parseFormTag(tag string) (string, formOptions) {
+	parts := strings.Split(tag, ",")
+
+	var options formOptions
+	for _, option := range parts[1:] {
+		switch option {
+		case "omitempty":
+			options.omitempty = true
+		}
+	}
+	return parts[0], options
+}
+
+func isEmptyFormValue(v reflect.Value) bool {
+	switch v.Type() {
+	case amountType:
+		return v.Interface().(Amount).IsZero()
+	case timeType:
+		return v.Interface().(time.Time).IsZero()
+	}
+
+	switch v.Kind() {
+	case reflect.String, reflect.Slice:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	}
+	return false
+}
+
+func formatFormValue(v reflect.Value) (string, error) {
+	switch v.Type() {
+	case amountType:
+		return v.Interface().(Amount).String(), nil
+	case timeType:
+		return formatUnixTime(v.Interface().(time.Time)), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return "1", nil
+		}
+		return "0", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported type %v", v.Type())
+}
@@ -0,0 +1,509 @@
+package coinpayments
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEncodeFormRequests(t *testing.T) {
+	newer := time.Unix(1600000000, 0)
+
+	tests := []struct {
+		name    string
+		request callable
+		cmd     string
+		want    url.Values
+	}{
+		{
+			name:    "balances",
+			request: &BalancesRequest{IncludeEmpty: true},
+			cmd:     "balances",
+			want:    url.Values{"all": {"1"}},
+		},
+		{
+			name:    "balances deprecated all",
+			request: &BalancesRequest{All: "1"},
+			cmd:     "balances",
+			want:    url.Values{"all": {"1"}},
+		},
+		{
+			name:    "balances empty",
+			request: &BalancesRequest{},
+			cmd:     "balances",
+			want:    url.Values{},
+		},
+		{
+			name:    "buy pbn tags",
+			request: &BuyPBNTagsRequest{Coin: "BTC", Count: 2},
+			cmd:     "buy_pbn_tags",
+			want:    url.Values{"coin": {"BTC"}, "num": {"2"}},
+		},
+		{
+			name:    "claim pbn coupon",
+			request: &ClaimPBNCouponRequest{Coupon: "FREE"},
+			cmd:     "claim_pbn_coupon",
+			want:    url.Values{"coupon": {"FREE"}},
+		},
+		{
+			name:    "claim pbn tag",
+			request: &ClaimPBNTagRequest{TagID: "tag", Name: "shop"},
+			cmd:     "claim_pbn_tag",
+			want:    url.Values{"tagid": {"tag"}, "name": {"shop"}},
+		},
+		{
+			name:    "convert",
+			request: &ConvertRequest{Amount: MustParseAmount("0.10000000"), From: "BTC", To: "LTC", DestTag: "7"},
+			cmd:     "convert",
+			want:    url.Values{"amount": {"0.1"}, "from": {"BTC"}, "to": {"LTC"}, "dest_tag": {"7"}},
+		},
+		{
+			name:    "convert limits",
+			request: &ConvertLimitsRequest{From: "BTC", To: "LTC"},
+			cmd:     "convert_limits",
+			want:    url.Values{"from": {"BTC"}, "to": {"LTC"}},
+		},
+		{
+			name: "create transaction",
+			request: &CreateTransactionRequest{
+				Amount:     MustParseAmount("10.00"),
+				Currency1:  "USD",
+				Currency2:  "BTC",
+				BuyerEmail: "buyer@example.com",
+				ItemName:   "Item",
+				IPNURL:     "https://example.com/ipn",
+			},
+			cmd: "create_transaction",
+			want: url.Values{
+				"amount":      {"10"},
+				"currency1":   {"USD"},
+				"currency2":   {"BTC"},
+				"buyer_email": {"buyer@example.com"},
+				"item_name":   {"Item"},
+				"ipn_url":     {"https://example.com/ipn"},
+			},
+		},
+		{
+			name:    "create transfer",
+			request: &CreateTransferRequest{Amount: MustParseAmount("1.5"), Currency: "BTC", PBNTag: "$shop", SkipConfirmation: true},
+			cmd:     "create_transfer",
+			want:    url.Values{"amount": {"1.5"}, "currency": {"BTC"}, "pbntag": {"$shop"}, "auto_confirm": {"1"}},
+		},
+		{
+			name:    "create transfer deprecated auto confirm",
+			request: &CreateTransferRequest{Amount: MustParseAmount("1.5"), Currency: "BTC", Merchant: "m", AutoConfirm: "1"},
+			cmd:     "create_transfer",
+			want:    url.Values{"amount": {"1.5"}, "currency": {"BTC"}, "merchant": {"m"}, "auto_confirm": {"1"}},
+		},
+		{
+			name: "create withdrawal",
+			request: &CreateWithdrawalRequest{
+				Amount:           MustParseAmount("0.00010000"),
+				Currency:         "BTC",
+				Address:          "addr",
+				SenderPaysFee:    true,
+				SkipConfirmation: true,
+			},
+			cmd: "create_withdrawal",
+			want: url.Values{
+				"amount":       {"0.0001"},
+				"currency":     {"BTC"},
+				"address":      {"addr"},
+				"add_tx_fee":   {"1"},
+				"auto_confirm": {"1"},
+			},
+		},
+		{
+			name:    "create withdrawal typed field wins over deprecated twin",
+			request: &CreateWithdrawalRequest{Amount: MustParseAmount("1"), Currency: "BTC", Address: "addr", SenderPaysFee: true, AddTxFee: "0"},
+			cmd:     "create_withdrawal",
+			want:    url.Values{"amount": {"1"}, "currency": {"BTC"}, "address": {"addr"}, "add_tx_fee": {"1"}},
+		},
+		{
+			name:    "delete pbn tag",
+			request: &DeletePBNTagRequest{TagID: "tag"},
+			cmd:     "delete_pbn_tag",
+			want:    url.Values{"tagid": {"tag"}},
+		},
+		{
+			name:    "get basic info",
+			request: &GetBasicInfoRequest{},
+			cmd:     "get_basic_info",
+			want:    url.Values{},
+		},
+		{
+			name:    "get callback address",
+			request: &GetCallbackAddressRequest{Currency: "BTC", Label: "order 1"},
+			cmd:     "get_callback_address",
+			want:    url.Values{"currency": {"BTC"}, "label": {"order 1"}},
+		},
+		{
+			name:    "get conversion info",
+			request: &GetConversionInfoRequest{ID: "c1"},
+			cmd:     "get_conversion_info",
+			want:    url.Values{"id": {"c1"}},
+		},
+		{
+			name:    "get deposit address",
+			request: &GetDepositAddressRequest{Currency: "LTCT"},
+			cmd:     "get_deposit_address",
+			want:    url.Values{"currency": {"LTCT"}},
+		},
+		{
+			name:    "get pbn info",
+			request: &GetPBNInfoRequest{PBNTag: "$shop"},
+			cmd:     "get_pbn_info",
+			want:    url.Values{"pbntag": {"$shop"}},
+		},
+		{
+			name:    "get pbn list",
+			request: &GetPBNListRequest{},
+			cmd:     "get_pbn_list",
+			want:    url.Values{},
+		},
+		{
+			name:    "get tx ids",
+			request: &GetTxIdsRequest{MaxResults: 50, Offset: 100, Since: newer},
+			cmd:     "get_tx_ids",
+			want:    url.Values{"limit": {"50"}, "start": {"100"}, "newer": {"1600000000"}},
+		},
+		{
+			name:    "get tx ids deprecated limit and start",
+			request: &GetTxIdsRequest{Limit: "10", Start: "20"},
+			cmd:     "get_tx_ids",
+			want:    url.Values{"limit": {"10"}, "start": {"20"}},
+		},
+		{
+			name:    "get tx info",
+			request: &GetTxInfoRequest{TXID: "tx", IncludeCheckout: true},
+			cmd:     "get_tx_info",
+			want:    url.Values{"txid": {"tx"}, "full": {"1"}},
+		},
+		{
+			name:    "get tx info multi",
+			request: &GetTxInfoMultiRequest{TXID: "a|b"},
+			cmd:     "get_tx_info_multi",
+			want:    url.Values{"txid": {"a|b"}},
+		},
+		{
+			name:    "get withdrawal history",
+			request: &GetWithdrawalHistoryRequest{MaxResults: 25, Since: newer},
+			cmd:     "get_withdrawal_history",
+			want:    url.Values{"limit": {"25"}, "newer": {"1600000000"}},
+		},
+		{
+			name:    "get withdrawal info",
+			request: &GetWithdrawalInfoRequest{ID: "w1"},
+			cmd:     "get_withdrawal_info",
+			want:    url.Values{"id": {"w1"}},
+		},
+		{
+			name:    "rates",
+			request: &RatesRequest{OmitDetails: true, Acceptance: RatesOnlyAccepted},
+			cmd:     "rates",
+			want:    url.Values{"short": {"1"}, "accepted": {"2"}},
+		},
+		{
+			name:    "rates omit details wins over short",
+			request: &RatesRequest{OmitDetails: true, Short: "0", Acceptance: RatesWithAccepted, Accepted: "2"},
+			cmd:     "rates",
+			want:    url.Values{"short": {"1"}, "accepted": {"1"}},
+		},
+		{
+			name:    "rates deprecated short when omit details is unset",
+			request: &RatesRequest{Short: "1"},
+			cmd:     "rates",
+			want:    url.Values{"short": {"1"}},
+		},
+		{
+			name:    "renew pbn tag",
+			request: &RenewPBNTagRequest{TagID: "tag", Coin: "BTC", NumYears: 2},
+			cmd:     "renew_pbn_tag",
+			want:    url.Values{"tagid": {"tag"}, "coin": {"BTC"}, "years": {"2"}},
+		},
+		{
+			name:    "update pbn tag",
+			request: &UpdatePBNTagRequest{TagID: "tag", Name: "shop", URL: "https://example.com"},
+			cmd:     "update_pbn_tag",
+			want:    url.Values{"tagid": {"tag"}, "name": {"shop"}, "url": {"https://example.com"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.request.command(); got != test.cmd {
+				t.Errorf("command() = %q, want %q", got, test.cmd)
+			}
+
+			got, err := encodeForm(test.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("encodeForm() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEncodeFormDeprecatedTwins(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		typed    callable
+		both     callable
+		twin     callable
+		empty    callable
+		want     string
+		twinWant string
+	}{
+		{
+			name:     "balances all",
+			key:      "all",
+			typed:    &BalancesRequest{IncludeEmpty: true},
+			both:     &BalancesRequest{IncludeEmpty: true, All: "0"},
+			twin:     &BalancesRequest{All: "0"},
+			empty:    &BalancesRequest{},
+			want:     "1",
+			twinWant: "0",
+		},
+		{
+			name:     "buy pbn tags num",
+			key:      "num",
+			typed:    &BuyPBNTagsRequest{Count: 2},
+			both:     &BuyPBNTagsRequest{Count: 2, Number: "5"},
+			twin:     &BuyPBNTagsRequest{Number: "5"},
+			empty:    &BuyPBNTagsRequest{},
+			want:     "2",
+			twinWant: "5",
+		},
+		{
+			name:     "create transfer auto_confirm",
+			key:      "auto_confirm",
+			typed:    &CreateTransferRequest{SkipConfirmation: true},
+			both:     &CreateTransferRequest{SkipConfirmation: true, AutoConfirm: "0"},
+			twin:     &CreateTransferRequest{AutoConfirm: "0"},
+			empty:    &CreateTransferRequest{},
+			want:     "1",
+			twinWant: "0",
+		},
+		{
+			name:     "create withdrawal add_tx_fee",
+			key:      "add_tx_fee",
+			typed:    &CreateWithdrawalRequest{SenderPaysFee: true},
+			both:     &CreateWithdrawalRequest{SenderPaysFee: true, AddTxFee: "0"},
+			twin:     &CreateWithdrawalRequest{AddTxFee: "0"},
+			empty:    &CreateWithdrawalRequest{},
+			want:     "1",
+			twinWant: "0",
+		},
+		{
+			name:     "create withdrawal auto_confirm",
+			key:      "auto_confirm",
+			typed:    &CreateWithdrawalRequest{SkipConfirmation: true},
+			both:     &CreateWithdrawalRequest{SkipConfirmation: true, AutoConfirm: "0"},
+			twin:     &CreateWithdrawalRequest{AutoConfirm: "0"},
+			empty:    &CreateWithdrawalRequest{},
+			want:     "1",
+			twinWant: "0",
+		},
+		{
+			name:     "get tx ids limit",
+			key:      "limit",
+			typed:    &GetTxIdsRequest{MaxResults: 50},
+			both:     &GetTxIdsRequest{MaxResults: 50, Limit: "10"},
+			twin:     &GetTxIdsRequest{Limit: "10"},
+			empty:    &GetTxIdsRequest{},
+			want:     "50",
+			twinWant: "10",
+		},
+		{
+			name:     "get tx ids start",
+			key:      "start",
+			typed:    &GetTxIdsRequest{Offset: 25},
+			both:     &GetTxIdsRequest{Offset: 25, Start: "5"},
+			twin:     &GetTxIdsRequest{Start: "5"},
+			empty:    &GetTxIdsRequest{},
+			want:     "25",
+			twinWant: "5",
+		},
+		{
+			name:     "get tx ids newer",
+			key:      "newer",
+			typed:    &GetTxIdsRequest{Since: time.Unix(1600000000, 0)},
+			both:     &GetTxIdsRequest{Since: time.Unix(1600000000, 0), Newer: "1500000000"},
+			twin:     &GetTxIdsRequest{Newer: "1500000000"},
+			empty:    &GetTxIdsRequest{},
+			want:     "1600000000",
+			twinWant: "1500000000",
+		},
+		{
+			name:     "get tx info full",
+			key:      "full",
+			typed:    &GetTxInfoRequest{IncludeCheckout: true},
+			both:     &GetTxInfoRequest{IncludeCheckout: true, Full: "0"},
+			twin:     &GetTxInfoRequest{Full: "0"},
+			empty:    &GetTxInfoRequest{},
+			want:     "1",
+			twinWant: "0",
+		},
+		{
+			name:     "get withdrawal history limit",
+			key:      "limit",
+			typed:    &GetWithdrawalHistoryRequest{MaxResults: 50},
+			both:     &GetWithdrawalHistoryRequest{MaxResults: 50, Limit: "10"},
+			twin:     &GetWithdrawalHistoryRequest{Limit: "10"},
+			empty:    &GetWithdrawalHistoryRequest{},
+			want:     "50",
+			twinWant: "10",
+		},
+		{
+			name:     "get withdrawal history start",
+			key:      "start",
+			typed:    &GetWithdrawalHistoryRequest{Offset: 25},
+			both:     &GetWithdrawalHistoryRequest{Offset: 25, Start: "5"},
+			twin:     &GetWithdrawalHistoryRequest{Start: "5"},
+			empty:    &GetWithdrawalHistoryRequest{},
+			want:     "25",
+			twinWant: "5",
+		},
+		{
+			name:     "get withdrawal history newer",
+			key:      "newer",
+			typed:    &GetWithdrawalHistoryRequest{Since: time.Unix(1600000000, 0)},
+			both:     &GetWithdrawalHistoryRequest{Since: time.Unix(1600000000, 0), Newer: "1500000000"},
+			twin:     &GetWithdrawalHistoryRequest{Newer: "1500000000"},
+			empty:    &GetWithdrawalHistoryRequest{},
+			want:     "1600000000",
+			twinWant: "1500000000",
+		},
+		{
+			name:     "rates short",
+			key:      "short",
+			typed:    &RatesRequest{OmitDetails: true},
+			both:     &RatesRequest{OmitDetails: true, Short: "0"},
+			twin:     &RatesRequest{Short: "0"},
+			empty:    &RatesRequest{},
+			want:     "1",
+			twinWant: "0",
+		},
+		{
+			name:     "rates accepted",
+			key:      "accepted",
+			typed:    &RatesRequest{Acceptance: RatesOnlyAccepted},
+			both:     &RatesRequest{Acceptance: RatesOnlyAccepted, Accepted: "1"},
+			twin:     &RatesRequest{Accepted: "1"},
+			empty:    &RatesRequest{},
+			want:     "2",
+			twinWant: "1",
+		},
+		{
+			name:     "renew pbn tag years",
+			key:      "years",
+			typed:    &RenewPBNTagRequest{NumYears: 3},
+			both:     &RenewPBNTagRequest{NumYears: 3, Years: "1"},
+			twin:     &RenewPBNTagRequest{Years: "1"},
+			empty:    &RenewPBNTagRequest{},
+			want:     "3",
+			twinWant: "1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encode := func(request callable) url.Values {
+				t.Helper()
+
+				values, err := encodeForm(request)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return values
+			}
+
+			if got := encode(test.typed).Get(test.key); got != test.want {
+				t.Errorf("typed field: %v = %q, want %q", test.key, got, test.want)
+			}
+			if got := encode(test.both).Get(test.key); got != test.want {
+				t.Errorf("typed field and its deprecated twin: %v = %q, want %q", test.key, got, test.want)
+			}
+			if got := encode(test.twin).Get(test.key); got != test.twinWant {
+				t.Errorf("deprecated twin alone: %v = %q, want %q", test.key, got, test.twinWant)
+			}
+			if _, ok := encode(test.empty)[test.key]; ok {
+				t.Errorf("empty request: %v should be left out", test.key)
+			}
+		})
+	}
+}
+
+type testFormWithdrawal struct {
+	Amount   Amount `form:"amount"`
+	Currency string `form:"currency"`
+	Address  string `form:"address,omitempty"`
+}
+
+type testFormRequest struct {
+	Enabled     bool      `form:"enabled"`
+	Disabled    bool      `form:"disabled"`
+	Skipped     bool      `form:"skipped,omitempty"`
+	Count       int       `form:"count"`
+	Size        uint8     `form:"size,omitempty"`
+	Total       Amount    `form:"total"`
+	Since       time.Time `form:"since"`
+	Untagged    string
+	Ignored     string               `form:"-"`
+	Withdrawals []testFormWithdrawal `form:"wd"`
+}
+
+func TestEncodeFormValues(t *testing.T) {
+	request := &testFormRequest{
+		Enabled:  true,
+		Total:    MustParseAmount("0.00000001"),
+		Untagged: "x",
+		Ignored:  "y",
+		Withdrawals: []testFormWithdrawal{
+			{Amount: MustParseAmount("1.50"), Currency: "BTC", Address: "a"},
+			{Amount: MustParseAmount("2"), Currency: "LTC"},
+		},
+	}
+
+	got, err := encodeForm(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := url.Values{
+		"enabled":         {"1"},
+		"disabled":        {"0"},
+		"count":           {"0"},
+		"total":           {"0.00000001"},
+		"since":           {"0"},
+		"wd[0][amount]":   {"1.5"},
+		"wd[0][currency]": {"BTC"},
+		"wd[0][address]":  {"a"},
+		"wd[1][amount]":   {"2"},
+		"wd[1][currency]": {"LTC"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("encodeForm() = %v, want %v", got, want)
+	}
+}
+
+func TestEncodeFormErrors(t *testing.T) {
+	var nilRequest *RatesRequest
+	if _, err := encodeForm(nilRequest); err == nil {
+		t.Error("expected an error encoding a nil request")
+	}
+	if _, err := encodeForm("rates"); err == nil {
+		t.Error("expected an error encoding a string")
+	}
+
+	unsupported := struct {
+		Rate float64 `form:"rate"`
+	}{Rate: 1.5}
+	if _, err := encodeForm(unsupported); err == nil {
+		t.Error("expected an error encoding an unsupported type")
+	}
+}
@@ -1,13 +1,18 @@
 package coinpayments
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 )
 
+//IPN is an instant payment notification sent by coinpayments
 type IPN struct {
+	values url.Values
 	ipnInformation
 	depositInformation            depositInformation
 	withdrawalInformation         withdrawalInformation
@@ -28,18 +33,19 @@ type ipnInformation struct {
 	Merchant   string `json:"merchant"`
 }
 
-type simpleIPN struct {
+//SimpleIPN is an IPN sent for a simple button payment
+type SimpleIPN struct {
 	ipnInformation
 	buyerInformation
 	shippingInformation
 	simpleButtonFields
 }
 
-func (i *IPN) ToSimpleIPN() (*simpleIPN, error) {
+func (i *IPN) ToSimpleIPN() (*SimpleIPN, error) {
 	if i.IPNType != "simple" {
 		return nil, fmt.Errorf("coinpayments: IPN type not 'simple'")
 	}
-	return &simpleIPN{
+	return &SimpleIPN{
 		ipnInformation:      i.ipnInformation,
 		buyerInformation:    i.buyerInformation,
 		shippingInformation: i.shippingInformation,
@@ -47,18 +53,19 @@ func (i *IPN) ToSimpleIPN() (*simpleIPN, error) {
 	}, nil
 }
 
-type buttonIPN struct {
+//ButtonIPN is an IPN sent for an advanced button payment
+type ButtonIPN struct {
 	ipnInformation
 	buyerInformation
 	shippingInformation
 	advancedButtonFields
 }
 
-func (i *IPN) ToButtonIPN() (*buttonIPN, error) {
+func (i *IPN) ToButtonIPN() (*ButtonIPN, error) {
 	if i.IPNType != "button" {
 		return nil, fmt.Errorf("coinpayments: IPN type not 'button'")
 	}
-	return &buttonIPN{
+	return &ButtonIPN{
 		ipnInformation:       i.ipnInformation,
 		buyerInformation:     i.buyerInformation,
 		shippingInformation:  i.shippingInformation,
@@ -66,18 +73,19 @@ func (i *IPN) ToButtonIPN() (*buttonIPN, error) {
 	}, nil
 }
 
-type cartIPN struct {
+//CartIPN is an IPN sent for a shopping cart button payment
+type CartIPN struct {
 	ipnInformation
 	buyerInformation
 	shippingInformation
 	shoppingCartButtonFields
 }
 
-func (i *IPN) ToCartIPN() (*cartIPN, error) {
+func (i *IPN) ToCartIPN() (*CartIPN, error) {
 	if i.IPNType != "cart" {
 		return nil, fmt.Errorf("coinpayments: IPN type not 'cart'")
 	}
-	return &cartIPN{
+	return &CartIPN{
 		ipnInformation:           i.ipnInformation,
 		buyerInformation:         i.buyerInformation,
 		shippingInformation:      i.shippingInformation,
@@ -85,18 +93,19 @@ func (i *IPN) ToCartIPN() (*cartIPN, error) {
 	}, nil
 }
 
-type donationIPN struct {
+//DonationIPN is an IPN sent for a donation button payment
+type DonationIPN struct {
 	ipnInformation
 	buyerInformation
 	shippingInformation
 	donationButtonFields
 }
 
-func (i *IPN) ToDonationIPN() (*donationIPN, error) {
+func (i *IPN) ToDonationIPN() (*DonationIPN, error) {
 	if i.IPNType != "donation" {
 		return nil, fmt.Errorf("coinpayments: IPN type not 'donation'")
 	}
-	return &donationIPN{
+	return &DonationIPN{
 		ipnInformation:       i.ipnInformation,
 		buyerInformation:     i.buyerInformation,
 		shippingInformation:  i.shippingInformation,
@@ -104,76 +113,154 @@ func (i *IPN) ToDonationIPN() (*donationIPN, error) {
 	}, nil
 }
 
-type depositIPN struct {
+//DepositIPN is an IPN sent for a deposit to a callback address
+type DepositIPN struct {
 	ipnInformation
 	depositInformation
 }
 
-func (i *IPN) ToDepositIPN() (*depositIPN, error) {
+func (i *IPN) ToDepositIPN() (*DepositIPN, error) {
 	if i.IPNType != "deposit" {
 		return nil, fmt.Errorf("coinpayments: IPN type not 'deposit'")
 	}
-	return &depositIPN{
+	return &DepositIPN{
 		ipnInformation:     i.ipnInformation,
 		depositInformation: i.depositInformation,
 	}, nil
 }
 
-type withdrawalIPN struct {
+//WithdrawalIPN is an IPN sent for a withdrawal
+type WithdrawalIPN struct {
 	ipnInformation
 	withdrawalInformation
 }
 
-func (i *IPN) ToWithdrawalIPN() (*withdrawalIPN, error) {
+func (i *IPN) ToWithdrawalIPN() (*WithdrawalIPN, error) {
 	if i.IPNType != "withdrawal" {
 		return nil, fmt.Errorf("coinpayments: IPN type not 'withdrawal'")
 	}
-	return &withdrawalIPN{
+	return &WithdrawalIPN{
 		ipnInformation:        i.ipnInformation,
 		withdrawalInformation: i.withdrawalInformation,
 	}, nil
 }
 
-type apiIPN struct {
+//ApiIPN is an IPN sent for a transaction created with the api
+type ApiIPN struct {
 	ipnInformation
 	apiGeneratedTransactionFields
 }
 
-func (i *IPN) ToApiIPN() (*apiIPN, error) {
+func (i *IPN) ToApiIPN() (*ApiIPN, error) {
 	if i.IPNType != "api" {
 		return nil, fmt.Errorf("coinpayments: IPN type not 'api'")
 	}
-	return &apiIPN{
+	return &ApiIPN{
 		ipnInformation:                i.ipnInformation,
 		apiGeneratedTransactionFields: i.apiGeneratedTransactionFields,
 	}, nil
 }
 
-func (c *Client) ParseIPN(r *http.Request, ipnSecret string) (*IPN, error) {
-	data, err := ioutil.ReadAll(r.Body)
+//ParseIPN verifies and parses an IPN request, applying the checks of any provided options.
+//Only 'hmac' IPNs are accepted unless WithHTTPAuth or WithIPNModes allows others, and an IPN whose ipn_mode is not
+//accepted is rejected before any credentials are checked. IPNs sent in 'hmac' mode are checked against ipnSecret,
+//and IPNs sent in 'httpauth' mode against the credentials given with WithHTTPAuth. Verification is only skipped
+//when WithoutVerification is provided.
+//The request body is restored after it is read so that it can be read again by later handlers
+func (c *Client) ParseIPN(r *http.Request, ipnSecret string, options ...IPNOption) (*IPN, error) {
+	config := newIPNConfig(options)
+
+	if r.Body == nil {
+		return nil, fmt.Errorf("coinpayments: ipn request has no body")
+	}
+
+	//one byte past the limit is read so that a body larger than it can be told apart from one that fills it
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, config.maxBodySize+1))
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(data))
 	if err != nil {
 		return nil, fmt.Errorf("coinpayments: error reading request body - %v", err)
 	}
 
-	if ipnSecret != "" {
-		hmac := r.Header.Get("HMAC")
+	return c.parseIPN(data, r.Header.Get("HMAC"), r.BasicAuth, ipnSecret, config)
+}
 
-		genHMAC, err := c.makeIPNHMAC(string(data), ipnSecret)
-		if err != nil {
-			return nil, fmt.Errorf("coinpayments: error generating ipn HMAC - %v", err)
-		}
+//ParseIPNBytes verifies and parses a raw IPN body and the value of its 'HMAC' header, such as one taken from a queue.
+//IPNs sent in 'httpauth' mode cannot be verified this way
+func (c *Client) ParseIPNBytes(data []byte, hmacHeader string, ipnSecret string, options ...IPNOption) (*IPN, error) {
+	return c.parseIPN(data, hmacHeader, nil, ipnSecret, newIPNConfig(options))
+}
 
-		if hmac != genHMAC {
-			return nil, fmt.Errorf("coinpayments: could not validate server HMAC")
-		}
+func (c *Client) parseIPN(data []byte, hmacHeader string, basicAuth func() (string, string, bool), ipnSecret string, config *ipnConfig) (*IPN, error) {
+	if int64(len(data)) > config.maxBodySize {
+		return nil, &IPNTooLargeError{Limit: config.maxBodySize}
 	}
 
 	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("coinpayments: error parsing ipn body - %v", err)
+	}
+
+	if err := c.authenticateIPN(data, values.Get("ipn_mode"), hmacHeader, basicAuth, ipnSecret, config); err != nil {
+		return nil, err
+	}
+
+	ipn, err := parseIPNValues(values, config.strict)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := config.verify(&ipn.ipnInformation); err != nil {
+		return nil, err
+	}
+
+	return ipn, nil
+}
+
+//parseIPNValues reads the fields of an IPN. Amounts and statuses that cannot be parsed are left as zero values, or
+//reported in a ValidationError along with the problems found by validateIPNValues when strict is set
+func parseIPNValues(values url.Values, strict bool) (*IPN, error) {
+	v := &validator{}
+	if strict {
+		validateIPNValues(v, values)
+	}
+	amount := func(key string) Amount {
+		value := values.Get(key)
+		if value == "" {
+			return Amount{}
+		}
+		a, err := ParseAmount(value)
+		if err != nil && strict {
+			v.add(key, "%q is not a decimal amount", value)
+		}
+		return a
+	}
+	satoshis := func(key string) Amount {
+		value := values.Get(key)
+		if value == "" {
+			return Amount{}
+		}
+		a, err := ParseSatoshis(value)
+		if err != nil && strict {
+			v.add(key, "%q is not an integer amount", value)
+		}
+		return a
+	}
+
+	status := func() int {
+		value := values.Get("status")
+		if value == "" {
+			return 0
+		}
+		i, err := strconv.Atoi(value)
+		if err != nil && strict {
+			v.add("status", "%q is not an integer", value)
+		}
+		return i
+	}
+
 	ipn := &IPN{
+		values: values,
 		ipnInformation: ipnInformation{
 			IPNVersion: values.Get("ipn_version"),
 			IPNType:    values.Get("ipn_type"),
@@ -204,19 +291,19 @@ func (c *Client) ParseIPN(r *http.Request, ipnSecret string) (*IPN, error) {
 		}
 
 		ipn.simpleButtonFields = simpleButtonFields{
-			Status:           values.Get("status"),
+			Status:           PaymentStatus(status()),
 			StatusText:       values.Get("status_text"),
 			TransactionID:    values.Get("txn_id"),
-			Currency1:        values.Get("currency1"),
-			Currency2:        values.Get("currency2"),
-			Amount1:          values.Get("amount1"),
-			Amount2:          values.Get("amount2"),
-			Subtotal:         values.Get("subtotal"),
-			Shipping:         values.Get("shipping"),
-			Tax:              values.Get("tax"),
-			Fee:              values.Get("fee"),
-			Net:              values.Get("net"),
-			ItemAmount:       values.Get("item_amount"),
+			Currency1:        Currency(values.Get("currency1")),
+			Currency2:        Currency(values.Get("currency2")),
+			Amount1:          amount("amount1"),
+			Amount2:          amount("amount2"),
+			Subtotal:         amount("subtotal"),
+			Shipping:         amount("shipping"),
+			Tax:              amount("tax"),
+			Fee:              amount("fee"),
+			Net:              amount("net"),
+			ItemAmount:       amount("item_amount"),
 			ItemName:         values.Get("item_name"),
 			ItemDescription:  values.Get("item_desc"),
 			ItemNumber:       values.Get("item_number"),
@@ -227,7 +314,7 @@ func (c *Client) ParseIPN(r *http.Request, ipnSecret string) (*IPN, error) {
 			Option2Name:      values.Get("on2"),
 			Option2Value:     values.Get("ov2"),
 			SendTransaction:  values.Get("send_tx"),
-			ReceivedAmount:   values.Get("received_amount"),
+			ReceivedAmount:   amount("received_amount"),
 			ReceivedConfirms: values.Get("received_confirms"),
 		}
 	case "button":
@@ -250,19 +337,19 @@ func (c *Client) ParseIPN(r *http.Request, ipnSecret string) (*IPN, error) {
 		}
 
 		ipn.advancedButtonFields = advancedButtonFields{
-			Status:           values.Get("status"),
+			Status:           PaymentStatus(status()),
 			StatusText:       values.Get("status_text"),
 			TransactionID:    values.Get("txn_id"),
-			Currency1:        values.Get("currency1"),
-			Currency2:        values.Get("currency2"),
-			Amount1:          values.Get("amount1"),
-			Amount2:          values.Get("amount2"),
-			Subtotal:         values.Get("subtotal"),
-			Shipping:         values.Get("shipping"),
-			Tax:              values.Get("tax"),
-			Fee:              values.Get("fee"),
-			Net:              values.Get("net"),
-			ItemAmount:       values.Get("item_amount"),
+			Currency1:        Currency(values.Get("currency1")),
+			Currency2:        Currency(values.Get("currency2")),
+			Amount1:          amount("amount1"),
+			Amount2:          amount("amount2"),
+			Subtotal:         amount("subtotal"),
+			Shipping:         amount("shipping"),
+			Tax:              amount("tax"),
+			Fee:              amount("fee"),
+			Net:              amount("net"),
+			ItemAmount:       amount("item_amount"),
 			ItemName:         values.Get("item_name"),
 			Quantity:         values.Get("quantity"),
 			ItemNumber:       values.Get("item_number"),
@@ -274,7 +361,7 @@ func (c *Client) ParseIPN(r *http.Request, ipnSecret string) (*IPN, error) {
 			Option2Value:     values.Get("ov2"),
 			Extra:            values.Get("extra"),
 			SendTransaction:  values.Get("send_tx"),
-			ReceivedAmount:   values.Get("received_amount"),
+			ReceivedAmount:   amount("received_amount"),
 			ReceivedConfirms: values.Get("received_confirms"),
 		}
 	case "cart":
@@ -297,19 +384,19 @@ func (c *Client) ParseIPN(r *http.Request, ipnSecret string) (*IPN, error) {
 		}
 
 		ipn.shoppingCartButtonFields = shoppingCartButtonFields{
-			Status:           values.Get("status"),
+			Status:           PaymentStatus(status()),
 			StatusText:       values.Get("status_text"),
 			TransactionID:    values.Get("txn_id"),
-			Currency1:        values.Get("currency1"),
-			Currency2:        values.Get("currency2"),
-			Amount1:          values.Get("amount1"),
-			Amount2:          values.Get("amount2"),
-			Subtotal:         values.Get("subtotal"),
-			Shipping:         values.Get("shipping"),
-			Tax:              values.Get("tax"),
-			Fee:              values.Get("fee"),
+			Currency1:        Currency(values.Get("currency1")),
+			Currency2:        Currency(values.Get("currency2")),
+			Amount1:          amount("amount1"),
+			Amount2:          amount("amount2"),
+			Subtotal:         amount("subtotal"),
+			Shipping:         amount("shipping"),
+			Tax:              amount("tax"),
+			Fee:              amount("fee"),
 			ItemName:         values.Get("item_name_#"),
-			ItemAmount:       values.Get("item_amount_#"),
+			ItemAmount:       amount("item_amount_#"),
 			ItemQuantity:     values.Get("item_quantity_#"),
 			ItemNumber:       values.Get("item_number_#"),
 			Option1Name:      values.Get("item_on1_#"),
@@ -320,7 +407,7 @@ func (c *Client) ParseIPN(r *http.Request, ipnSecret string) (*IPN, error) {
 			Custom:           values.Get("custom"),
 			Extra:            values.Get("extra"),
 			SendTransaction:  values.Get("send_tx"),
-			ReceivedAmount:   values.Get("received_amount"),
+			ReceivedAmount:   amount("received_amount"),
 			ReceivedConfirms: values.Get("received_confirms"),
 		}
 	case "donation":
@@ -343,18 +430,18 @@ func (c *Client) ParseIPN(r *http.Request, ipnSecret string) (*IPN, error) {
 		}
 
 		ipn.donationButtonFields = donationButtonFields{
-			Status:           values.Get("status"),
+			Status:           PaymentStatus(status()),
 			StatusText:       values.Get("status_text"),
 			TransactionID:    values.Get("txn_id"),
-			Currency1:        values.Get("currency1"),
-			Currency2:        values.Get("currency2"),
-			Amount1:          values.Get("amount1"),
-			Amount2:          values.Get("amount2"),
-			Subtotal:         values.Get("subtotal"),
-			Shipping:         values.Get("shipping"),
-			Tax:              values.Get("tax"),
-			Fee:              values.Get("fee"),
-			Net:              values.Get("net"),
+			Currency1:        Currency(values.Get("currency1")),
+			Currency2:        Currency(values.Get("currency2")),
+			Amount1:          amount("amount1"),
+			Amount2:          amount("amount2"),
+			Subtotal:         amount("subtotal"),
+			Shipping:         amount("shipping"),
+			Tax:              amount("tax"),
+			Fee:              amount("fee"),
+			Net:              amount("net"),
 			ItemName:         values.Get("item_name"),
 			ItemNumber:       values.Get("item_number"),
 			Invoice:          values.Get("invoice"),
@@ -365,7 +452,7 @@ func (c *Client) ParseIPN(r *http.Request, ipnSecret string) (*IPN, error) {
 			Option2Value:     values.Get("ov2"),
 			Extra:            values.Get("extra"),
 			SendTransaction:  values.Get("send_tx"),
-			ReceivedAmount:   values.Get("received_amount"),
+			ReceivedAmount:   amount("received_amount"),
 			ReceivedConfirms: values.Get("received_confirms"),
 		}
 	case "deposit":
@@ -373,41 +460,41 @@ func (c *Client) ParseIPN(r *http.Request, ipnSecret string) (*IPN, error) {
 			TransactionID: values.Get("txn_id"),
 			Address:       values.Get("address"),
 			DestTag:       values.Get("dest_tag"),
-			Status:        values.Get("status"),
+			Status:        PaymentStatus(status()),
 			StatusText:    values.Get("status_text"),
-			Currency:      values.Get("currency"),
+			Currency:      Currency(values.Get("currency")),
 			Confirms:      values.Get("confirms"),
-			Amount:        values.Get("amount"),
-			Amounti:       values.Get("amounti"),
-			Fee:           values.Get("fee"),
-			Feei:          values.Get("feei"),
-			FiatCoin:      values.Get("fiat_coin"),
-			FiatAmount:    values.Get("fiat_amount"),
-			FiatAmounti:   values.Get("fiat_amounti"),
-			FiatFee:       values.Get("fiat_fee"),
-			FiatFeei:      values.Get("fiat_feei"),
+			Amount:        amount("amount"),
+			Amounti:       satoshis("amounti"),
+			Fee:           amount("fee"),
+			Feei:          satoshis("feei"),
+			FiatCoin:      Currency(values.Get("fiat_coin")),
+			FiatAmount:    amount("fiat_amount"),
+			FiatAmounti:   satoshis("fiat_amounti"),
+			FiatFee:       amount("fiat_fee"),
+			FiatFeei:      satoshis("fiat_feei"),
 		}
 	case "withdrawal":
 		ipn.withdrawalInformation = withdrawalInformation{
 			ID:            values.Get("id"),
-			Status:        values.Get("status"),
+			Status:        WithdrawalStatus(status()),
 			StatusText:    values.Get("status_text"),
 			Address:       values.Get("address"),
 			TransactionID: values.Get("txn_id"),
-			Currency:      values.Get("currency"),
-			Amount:        values.Get("amount"),
-			Amounti:       values.Get("amounti"),
+			Currency:      Currency(values.Get("currency")),
+			Amount:        amount("amount"),
+			Amounti:       satoshis("amounti"),
 		}
 	case "api":
 		ipn.apiGeneratedTransactionFields = apiGeneratedTransactionFields{
-			Status:           values.Get("status"),
+			Status:           PaymentStatus(status()),
 			StatusText:       values.Get("status_text"),
 			TransactionID:    values.Get("txn_id"),
-			Currency1:        values.Get("currency1"),
-			Currency2:        values.Get("currency2"),
-			Amount1:          values.Get("amount1"),
-			Amount2:          values.Get("amount2"),
-			Fee:              values.Get("fee"),
+			Currency1:        Currency(values.Get("currency1")),
+			Currency2:        Currency(values.Get("currency2")),
+			Amount1:          amount("amount1"),
+			Amount2:          amount("amount2"),
+			Fee:              amount("fee"),
 			BuyerName:        values.Get("buyer_name"),
 			Email:            values.Get("email"),
 			ItemName:         values.Get("item_name"),
@@ -415,42 +502,45 @@ func (c *Client) ParseIPN(r *http.Request, ipnSecret string) (*IPN, error) {
 			Invoice:          values.Get("invoice"),
 			Custom:           values.Get("custom"),
 			SendTransaction:  values.Get("send_tx"),
-			ReceivedAmount:   values.Get("received_amount"),
+			ReceivedAmount:   amount("received_amount"),
 			ReceivedConfirms: values.Get("received_confirms"),
 		}
 	}
 
+	if err := v.err(); err != nil {
+		return nil, err
+	}
 	return ipn, nil
 }
 
 type depositInformation struct {
-	TransactionID string `json:"txn_id"`
-	Address       string `json:"address"`
-	DestTag       string `json:"dest_tag"`
-	Status        string `json:"status"`
-	StatusText    string `json:"status_text"`
-	Currency      string `json:"currency"`
-	Confirms      string `json:"confirms"`
-	Amount        string `json:"amount"`
-	Amounti       string `json:"amounti"`
-	Fee           string `json:"fee"`
-	Feei          string `json:"feei"`
-	FiatCoin      string `json:"fiat_coin"`
-	FiatAmount    string `json:"fiat_amount"`
-	FiatAmounti   string `json:"fiat_amounti"`
-	FiatFee       string `json:"fiat_fee"`
-	FiatFeei      string `json:"fiat_feei"`
+	TransactionID string        `json:"txn_id"`
+	Address       string        `json:"address"`
+	DestTag       string        `json:"dest_tag"`
+	Status        PaymentStatus `json:"status"`
+	StatusText    string        `json:"status_text"`
+	Currency      Currency      `json:"currency"`
+	Confirms      string        `json:"confirms"`
+	Amount        Amount        `json:"amount"`
+	Amounti       Amount        `json:"amounti"`
+	Fee           Amount        `json:"fee"`
+	Feei          Amount        `json:"feei"`
+	FiatCoin      Currency      `json:"fiat_coin"`
+	FiatAmount    Amount        `json:"fiat_amount"`
+	FiatAmounti   Amount        `json:"fiat_amounti"`
+	FiatFee       Amount        `json:"fiat_fee"`
+	FiatFeei      Amount        `json:"fiat_feei"`
 }
 
 type withdrawalInformation struct {
-	ID            string `json:"id"`
-	Status        string `json:"status"`
-	StatusText    string `json:"status_text"`
-	Address       string `json:"address"`
-	TransactionID string `json:"txn_id"`
-	Currency      string `json:"currency"`
-	Amount        string `json:"amount"`
-	Amounti       string `json:"amounti"`
+	ID            string           `json:"id"`
+	Status        WithdrawalStatus `json:"status"`
+	StatusText    string           `json:"status_text"`
+	Address       string           `json:"address"`
+	TransactionID string           `json:"txn_id"`
+	Currency      Currency         `json:"currency"`
+	Amount        Amount           `json:"amount"`
+	Amounti       Amount           `json:"amounti"`
 }
 
 type buyerInformation struct {
@@ -472,133 +562,133 @@ type shippingInformation struct {
 }
 
 type simpleButtonFields struct {
-	Status           string `json:"status"`
-	StatusText       string `json:"status_text"`
-	TransactionID    string `json:"txn_id"`
-	Currency1        string `json:"currency1"`
-	Currency2        string `json:"currency2"`
-	Amount1          string `json:"amount1"`
-	Amount2          string `json:"amount2"`
-	Subtotal         string `json:"subtotal"`
-	Shipping         string `json:"shipping"`
-	Tax              string `json:"tax"`
-	Fee              string `json:"fee"`
-	Net              string `json:"net"`
-	ItemAmount       string `json:"item_amount"`
-	ItemName         string `json:"item_name"`
-	ItemDescription  string `json:"item_desc"`
-	ItemNumber       string `json:"item_number"`
-	Invoice          string `json:"invoice"`
-	Custom           string `json:"custom"`
-	Option1Name      string `json:"on1"`
-	Option1Value     string `json:"ov1"`
-	Option2Name      string `json:"on2"`
-	Option2Value     string `json:"ov2"`
-	SendTransaction  string `json:"send_tx"`
-	ReceivedAmount   string `json:"received_amount"`
-	ReceivedConfirms string `json:"received_confirms"`
+	Status           PaymentStatus `json:"status"`
+	StatusText       string        `json:"status_text"`
+	TransactionID    string        `json:"txn_id"`
+	Currency1        Currency      `json:"currency1"`
+	Currency2        Currency      `json:"currency2"`
+	Amount1          Amount        `json:"amount1"`
+	Amount2          Amount        `json:"amount2"`
+	Subtotal         Amount        `json:"subtotal"`
+	Shipping         Amount        `json:"shipping"`
+	Tax              Amount        `json:"tax"`
+	Fee              Amount        `json:"fee"`
+	Net              Amount        `json:"net"`
+	ItemAmount       Amount        `json:"item_amount"`
+	ItemName         string        `json:"item_name"`
+	ItemDescription  string        `json:"item_desc"`
+	ItemNumber       string        `json:"item_number"`
+	Invoice          string        `json:"invoice"`
+	Custom           string        `json:"custom"`
+	Option1Name      string        `json:"on1"`
+	Option1Value     string        `json:"ov1"`
+	Option2Name      string        `json:"on2"`
+	Option2Value     string        `json:"ov2"`
+	SendTransaction  string        `json:"send_tx"`
+	ReceivedAmount   Amount        `json:"received_amount"`
+	ReceivedConfirms string        `json:"received_confirms"`
 }
 
 type advancedButtonFields struct {
-	Status           string `json:"status"`
-	StatusText       string `json:"status_text"`
-	TransactionID    string `json:"txn_id"`
-	Currency1        string `json:"currency1"`
-	Currency2        string `json:"currency2"`
-	Amount1          string `json:"amount1"`
-	Amount2          string `json:"amount2"`
-	Subtotal         string `json:"subtotal"`
-	Shipping         string `json:"shipping"`
-	Tax              string `json:"tax"`
-	Fee              string `json:"fee"`
-	Net              string `json:"net"`
-	ItemAmount       string `json:"item_amount"`
-	ItemName         string `json:"item_name"`
-	Quantity         string `json:"quantity"`
-	ItemNumber       string `json:"item_number"`
-	Invoice          string `json:"invoice"`
-	Custom           string `json:"custom"`
-	Option1Name      string `json:"on1"`
-	Option1Value     string `json:"ov1"`
-	Option2Name      string `json:"on2"`
-	Option2Value     string `json:"ov2"`
-	Extra            string `json:"extra"`
-	SendTransaction  string `json:"send_tx"`
-	ReceivedAmount   string `json:"received_amount"`
-	ReceivedConfirms string `json:"received_confirms"`
+	Status           PaymentStatus `json:"status"`
+	StatusText       string        `json:"status_text"`
+	TransactionID    string        `json:"txn_id"`
+	Currency1        Currency      `json:"currency1"`
+	Currency2        Currency      `json:"currency2"`
+	Amount1          Amount        `json:"amount1"`
+	Amount2          Amount        `json:"amount2"`
+	Subtotal         Amount        `json:"subtotal"`
+	Shipping         Amount        `json:"shipping"`
+	Tax              Amount        `json:"tax"`
+	Fee              Amount        `json:"fee"`
+	Net              Amount        `json:"net"`
+	ItemAmount       Amount        `json:"item_amount"`
+	ItemName         string        `json:"item_name"`
+	Quantity         string        `json:"quantity"`
+	ItemNumber       string        `json:"item_number"`
+	Invoice          string        `json:"invoice"`
+	Custom           string        `json:"custom"`
+	Option1Name      string        `json:"on1"`
+	Option1Value     string        `json:"ov1"`
+	Option2Name      string        `json:"on2"`
+	Option2Value     string        `json:"ov2"`
+	Extra            string        `json:"extra"`
+	SendTransaction  string        `json:"send_tx"`
+	ReceivedAmount   Amount        `json:"received_amount"`
+	ReceivedConfirms string        `json:"received_confirms"`
 }
 
 type shoppingCartButtonFields struct {
-	Status           string `json:"status"`
-	StatusText       string `json:"status_text"`
-	TransactionID    string `json:"txn_id"`
-	Currency1        string `json:"currency1"`
-	Currency2        string `json:"currency2"`
-	Amount1          string `json:"amount1"`
-	Amount2          string `json:"amount2"`
-	Subtotal         string `json:"subtotal"`
-	Shipping         string `json:"shipping"`
-	Tax              string `json:"tax"`
-	Fee              string `json:"fee"`
-	ItemName         string `json:"item_name_#"`
-	ItemAmount       string `json:"item_amount_#"`
-	ItemQuantity     string `json:"item_quantity_#"`
-	ItemNumber       string `json:"item_number_#"`
-	Option1Name      string `json:"item_on1_#"`
-	Option1Value     string `json:"item_ov1_#"`
-	Option2Name      string `json:"item_on2_#"`
-	Option2Value     string `json:"item_ov2_#"`
-	Invoice          string `json:"invoice"`
-	Custom           string `json:"custom"`
-	Extra            string `json:"extra"`
-	SendTransaction  string `json:"send_tx"`
-	ReceivedAmount   string `json:"received_amount"`
-	ReceivedConfirms string `json:"received_confirms"`
+	Status           PaymentStatus `json:"status"`
+	StatusText       string        `json:"status_text"`
+	TransactionID    string        `json:"txn_id"`
+	Currency1        Currency      `json:"currency1"`
+	Currency2        Currency      `json:"currency2"`
+	Amount1          Amount        `json:"amount1"`
+	Amount2          Amount        `json:"amount2"`
+	Subtotal         Amount        `json:"subtotal"`
+	Shipping         Amount        `json:"shipping"`
+	Tax              Amount        `json:"tax"`
+	Fee              Amount        `json:"fee"`
+	ItemName         string        `json:"item_name_#"`
+	ItemAmount       Amount        `json:"item_amount_#"`
+	ItemQuantity     string        `json:"item_quantity_#"`
+	ItemNumber       string        `json:"item_number_#"`
+	Option1Name      string        `json:"item_on1_#"`
+	Option1Value     string        `json:"item_ov1_#"`
+	Option2Name      string        `json:"item_on2_#"`
+	Option2Value     string        `json:"item_ov2_#"`
+	Invoice          string        `json:"invoice"`
+	Custom           string        `json:"custom"`
+	Extra            string        `json:"extra"`
+	SendTransaction  string        `json:"send_tx"`
+	ReceivedAmount   Amount        `json:"received_amount"`
+	ReceivedConfirms string        `json:"received_confirms"`
 }
 
 type donationButtonFields struct {
-	Status           string `json:"status"`
-	StatusText       string `json:"status_text"`
-	TransactionID    string `json:"txn_id"`
-	Currency1        string `json:"currency1"`
-	Currency2        string `json:"currency2"`
-	Amount1          string `json:"amount1"`
-	Amount2          string `json:"amount2"`
-	Subtotal         string `json:"subtotal"`
-	Shipping         string `json:"shipping"`
-	Tax              string `json:"tax"`
-	Fee              string `json:"fee"`
-	Net              string `json:"net"`
-	ItemName         string `json:"item_name"`
-	ItemNumber       string `json:"item_number"`
-	Invoice          string `json:"invoice"`
-	Custom           string `json:"custom"`
-	Option1Name      string `json:"on1"`
-	Option1Value     string `json:"ov1"`
-	Option2Name      string `json:"on2"`
-	Option2Value     string `json:"ov2"`
-	Extra            string `json:"extra"`
-	SendTransaction  string `json:"send_tx"`
-	ReceivedAmount   string `json:"received_amount"`
-	ReceivedConfirms string `json:"received_confirms"`
+	Status           PaymentStatus `json:"status"`
+	StatusText       string        `json:"status_text"`
+	TransactionID    string        `json:"txn_id"`
+	Currency1        Currency      `json:"currency1"`
+	Currency2        Currency      `json:"currency2"`
+	Amount1          Amount        `json:"amount1"`
+	Amount2          Amount        `json:"amount2"`
+	Subtotal         Amount        `json:"subtotal"`
+	Shipping         Amount        `json:"shipping"`
+	Tax              Amount        `json:"tax"`
+	Fee              Amount        `json:"fee"`
+	Net              Amount        `json:"net"`
+	ItemName         string        `json:"item_name"`
+	ItemNumber       string        `json:"item_number"`
+	Invoice          string        `json:"invoice"`
+	Custom           string        `json:"custom"`
+	Option1Name      string        `json:"on1"`
+	Option1Value     string        `json:"ov1"`
+	Option2Name      string        `json:"on2"`
+	Option2Value     string        `json:"ov2"`
+	Extra            string        `json:"extra"`
+	SendTransaction  string        `json:"send_tx"`
+	ReceivedAmount   Amount        `json:"received_amount"`
+	ReceivedConfirms string        `json:"received_confirms"`
 }
 
 type apiGeneratedTransactionFields struct {
-	Status           string `json:"status"`
-	StatusText       string `json:"status_text"`
-	TransactionID    string `json:"txn_id"`
-	Currency1        string `json:"currency1"`
-	Currency2        string `json:"currency2"`
-	Amount1          string `json:"amount1"`
-	Amount2          string `json:"amount2"`
-	Fee              string `json:"fee"`
-	BuyerName        string `json:"buyer_name"`
-	Email            string `json:"email"`
-	ItemName         string `json:"item_name"`
-	ItemNumber       string `json:"item_number"`
-	Invoice          string `json:"invoice"`
-	Custom           string `json:"custom"`
-	SendTransaction  string `json:"send_tx"`
-	ReceivedAmount   string `json:"received_amount"`
-	ReceivedConfirms string `json:"received_confirms"`
+	Status           PaymentStatus `json:"status"`
+	StatusText       string        `json:"status_text"`
+	TransactionID    string        `json:"txn_id"`
+	Currency1        Currency      `json:"currency1"`
+	Currency2        Currency      `json:"currency2"`
+	Amount1          Amount        `json:"amount1"`
+	Amount2          Amount        `json:"amount2"`
+	Fee              Amount        `json:"fee"`
+	BuyerName        string        `json:"buyer_name"`
+	Email            string        `json:"email"`
+	ItemName         string        `json:"item_name"`
+	ItemNumber       string        `json:"item_number"`
+	Invoice          string        `json:"invoice"`
+	Custom           string        `json:"custom"`
+	SendTransaction  string        `json:"send_tx"`
+	ReceivedAmount   Amount        `json:"received_amount"`
+	ReceivedConfirms string        `json:"received_confirms"`
 }
@@ -0,0 +1,174 @@
+package coinpayments
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+//SignIPN returns the HMAC coinpayments sends in the 'HMAC' header for an IPN body
+func SignIPN(data []byte, ipnSecret string) string {
+	hash := hmac.New(sha512.New, []byte(ipnSecret))
+	hash.Write(data)
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+//IPNBuilder builds signed IPNs of any type, for tests and local simulations
+type IPNBuilder struct {
+	values url.Values
+}
+
+//NewIPNBuilder returns a new IPNBuilder for the ipn type, filled with sensible defaults for that type
+func NewIPNBuilder(ipnType string) *IPNBuilder {
+	id, err := randomID()
+	if err != nil {
+		id = fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+	txnID := "CPTEST" + strings.ToUpper(id[:16])
+
+	values := url.Values{}
+	values.Set("ipn_version", "1.0")
+	values.Set("ipn_type", ipnType)
+	values.Set("ipn_mode", ipnModeHMAC)
+	values.Set("ipn_id", id)
+	values.Set("merchant", "TESTMERCHANT")
+
+	buyer := func() {
+		values.Set("first_name", "Test")
+		values.Set("last_name", "Buyer")
+		values.Set("email", "buyer@example.com")
+	}
+	button := func() {
+		buyer()
+		values.Set("status", "100")
+		values.Set("status_text", "Complete")
+		values.Set("txn_id", txnID)
+		values.Set("currency1", "USD")
+		values.Set("currency2", "BTC")
+		values.Set("amount1", "10.00")
+		values.Set("amount2", "0.00100000")
+		values.Set("subtotal", "10.00")
+		values.Set("shipping", "0.00")
+		values.Set("tax", "0.00")
+		values.Set("fee", "0.00000500")
+		values.Set("net", "0.00099500")
+		values.Set("item_name", "Test Item")
+		values.Set("received_amount", "0.00100000")
+		values.Set("received_confirms", "3")
+	}
+
+	switch ipnType {
+	case "simple", "donation":
+		button()
+	case "button":
+		button()
+		values.Set("quantity", "1")
+	case "cart":
+		button()
+		values.Del("item_name")
+		values.Del("net")
+		values.Set("item_name_1", "Test Item")
+		values.Set("item_amount_1", "10.00")
+		values.Set("item_quantity_1", "1")
+	case "deposit":
+		values.Set("txn_id", txnID)
+		values.Set("address", "mzTestDepositAddress")
+		values.Set("status", "100")
+		values.Set("status_text", "Deposit confirmed")
+		values.Set("currency", "BTC")
+		values.Set("confirms", "3")
+		values.Set("amount", "0.00100000")
+		values.Set("amounti", "100000")
+		values.Set("fee", "0.00000500")
+		values.Set("feei", "500")
+	case "withdrawal":
+		values.Set("id", txnID)
+		values.Set("status", "2")
+		values.Set("status_text", "Complete")
+		values.Set("address", "mzTestWithdrawalAddress")
+		values.Set("txn_id", id)
+		values.Set("currency", "BTC")
+		values.Set("amount", "0.00100000")
+		values.Set("amounti", "100000")
+	case "api":
+		values.Set("status", "100")
+		values.Set("status_text", "Complete")
+		values.Set("txn_id", txnID)
+		values.Set("currency1", "USD")
+		values.Set("currency2", "BTC")
+		values.Set("amount1", "10.00")
+		values.Set("amount2", "0.00100000")
+		values.Set("fee", "0.00000500")
+		values.Set("buyer_name", "Test Buyer")
+		values.Set("email", "buyer@example.com")
+		values.Set("item_name", "Test Item")
+		values.Set("received_amount", "0.00100000")
+		values.Set("received_confirms", "3")
+	}
+
+	return &IPNBuilder{values: values}
+}
+
+//Set sets the field to the value, replacing any default
+func (b *IPNBuilder) Set(key, value string) *IPNBuilder {
+	b.values.Set(key, value)
+	return b
+}
+
+//Del removes the field
+func (b *IPNBuilder) Del(key string) *IPNBuilder {
+	b.values.Del(key)
+	return b
+}
+
+//Values returns a copy of the IPN's fields
+func (b *IPNBuilder) Values() url.Values {
+	values := url.Values{}
+	for k, v := range b.values {
+		values[k] = append([]string(nil), v...)
+	}
+	return values
+}
+
+//Encode returns the IPN as a form encoded body
+func (b *IPNBuilder) Encode() string {
+	return b.values.Encode()
+}
+
+//Sign returns the form encoded body and the HMAC header for it
+func (b *IPNBuilder) Sign(ipnSecret string) (string, string) {
+	body := b.Encode()
+	return body, SignIPN([]byte(body), ipnSecret)
+}
+
+//NewRequest returns a signed IPN request for the url
+func (b *IPNBuilder) NewRequest(url, ipnSecret string) (*http.Request, error) {
+	body, sig := b.Sign(ipnSecret)
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("coinpayments: error making ipn request - %v", err)
+	}
+
+	req.Header.Set("HMAC", sig)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
+
+//Post signs the IPN and posts it to the url with the http client
+func (b *IPNBuilder) Post(client *http.Client, url, ipnSecret string) (*http.Response, error) {
+	req, err := b.NewRequest(url, ipnSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("coinpayments: error posting ipn - %v", err)
+	}
+	return resp, nil
+}
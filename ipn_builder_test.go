@@ -0,0 +1,97 @@
+package coinpayments
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newBuiltIPNRequest(t *testing.T, builder *IPNBuilder) *http.Request {
+	t.Helper()
+
+	r, err := builder.NewRequest("http://localhost/ipn", testIPNSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestSignIPN(t *testing.T) {
+	want := "d305138722a0b7335a6211f182a3ce476393990e21aa7060673eae394ffa99dd28519864bcb30cdf9db4c5a51fee158a85894bf035f5097856a41f94600b1927"
+	if got := SignIPN([]byte("ipn_type=deposit"), "secret"); got != want {
+		t.Errorf("SignIPN = %v, want %v", got, want)
+	}
+}
+
+func TestIPNBuilderDefaults(t *testing.T) {
+	client := NewClient("public", "private")
+
+	for _, ipnType := range []string{"api", "deposit", "withdrawal", "simple", "button", "cart", "donation"} {
+		t.Run(ipnType, func(t *testing.T) {
+			builder := NewIPNBuilder(ipnType)
+			values := builder.Values()
+			if values.Get("merchant") != "TESTMERCHANT" || values.Get("ipn_mode") != ipnModeHMAC || values.Get("ipn_id") == "" {
+				t.Errorf("unexpected defaults %v", values)
+			}
+
+			ipn, err := client.ParseIPN(newBuiltIPNRequest(t, builder), testIPNSecret, WithStrict())
+			if err != nil {
+				t.Fatalf("default %v ipn failed strict parsing: %v", ipnType, err)
+			}
+			if ipn.IPNType != ipnType {
+				t.Errorf("IPNType = %q, want %q", ipn.IPNType, ipnType)
+			}
+		})
+	}
+
+	if NewIPNBuilder("deposit").Values().Get("ipn_id") == NewIPNBuilder("deposit").Values().Get("ipn_id") {
+		t.Error("builders should get distinct ipn ids")
+	}
+}
+
+func TestIPNBuilderSetAndDel(t *testing.T) {
+	builder := NewIPNBuilder("deposit").Set("amount", "2.5").Del("fee")
+
+	values := builder.Values()
+	if values.Get("amount") != "2.5" {
+		t.Errorf("amount = %q, want 2.5", values.Get("amount"))
+	}
+	if _, ok := values["fee"]; ok {
+		t.Error("fee should be removed")
+	}
+
+	values.Set("amount", "9")
+	if builder.Values().Get("amount") != "2.5" {
+		t.Error("Values should return a copy")
+	}
+
+	body, hmac := builder.Sign(testIPNSecret)
+	if body != builder.Encode() || hmac != SignIPN([]byte(body), testIPNSecret) {
+		t.Errorf("Sign = %q, %q, want the encoded body and its hmac", body, hmac)
+	}
+}
+
+func TestIPNBuilderPost(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	builder := NewIPNBuilder("withdrawal")
+	resp, err := builder.Post(server.Client(), server.URL, testIPNSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if received.Method != http.MethodPost || received.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		t.Errorf("got %v with Content-Type %q", received.Method, received.Header.Get("Content-Type"))
+	}
+	if string(body) != builder.Encode() || received.Header.Get("HMAC") != SignIPN(body, testIPNSecret) {
+		t.Error("posted body or HMAC does not match the builder")
+	}
+}
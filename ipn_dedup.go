@@ -0,0 +1,111 @@
+package coinpayments
+
+import (
+	"fmt"
+	"sync"
+)
+
+//IPNInFlightError is returned by DeduplicateIPN when an IPN with the same key is still being processed, so that the
+//resend is answered with an error and arrives again once the first delivery has succeeded or failed
+type IPNInFlightError struct {
+	Key string
+}
+
+func (e *IPNInFlightError) Error() string {
+	return fmt.Sprintf("coinpayments: ipn %v is already being processed", e.Key)
+}
+
+//inFlightIPNs holds the keys of the IPNs DeduplicateIPN is running fn for
+var inFlightIPNs = struct {
+	sync.Mutex
+	keys map[string]bool
+}{keys: make(map[string]bool)}
+
+//DeduplicateIPN calls fn unless an IPN with the same ipn_id, or for the same transaction and status, was already
+//processed, in which case it reports a duplicate without calling fn. An IPN whose key is still being processed by
+//another call returns an IPNInFlightError instead, as that call may yet fail. If fn fails the IPN is forgotten
+//again so that the resend coinpayments makes is processed
+func DeduplicateIPN(store IPNStore, ipn *IPN, fn func(ipn *IPN) error) (bool, error) {
+	var keys []string
+	if ipn.IPNId != "" {
+		keys = append(keys, "ipn:"+ipn.IPNId)
+	}
+	if txnID, status := ipn.transaction(); txnID != "" {
+		keys = append(keys, fmt.Sprintf("txn:%v:%v:%v", ipn.IPNType, txnID, status))
+	}
+
+	if err := claimIPNKeys(keys); err != nil {
+		return false, err
+	}
+	defer releaseIPNKeys(keys)
+
+	var added []string
+	forget := func() {
+		for _, key := range added {
+			_ = store.Remove(key)
+		}
+	}
+
+	for _, key := range keys {
+		ok, err := store.Add(key)
+		if err != nil {
+			forget()
+			return false, fmt.Errorf("coinpayments: error recording ipn - %v", err)
+		}
+		if !ok {
+			return true, nil
+		}
+		added = append(added, key)
+	}
+
+	if err := fn(ipn); err != nil {
+		forget()
+		return false, err
+	}
+	return false, nil
+}
+
+func claimIPNKeys(keys []string) error {
+	inFlightIPNs.Lock()
+	defer inFlightIPNs.Unlock()
+
+	for _, key := range keys {
+		if inFlightIPNs.keys[key] {
+			return &IPNInFlightError{Key: key}
+		}
+	}
+	for _, key := range keys {
+		inFlightIPNs.keys[key] = true
+	}
+	return nil
+}
+
+func releaseIPNKeys(keys []string) {
+	inFlightIPNs.Lock()
+	defer inFlightIPNs.Unlock()
+
+	for _, key := range keys {
+		delete(inFlightIPNs.keys, key)
+	}
+}
+
+//transaction returns the id and status of the transaction the IPN is about
+func (i *IPN) transaction() (string, int) {
+	switch i.IPNType {
+	case "simple":
+		return i.simpleButtonFields.TransactionID, int(i.simpleButtonFields.Status)
+	case "button":
+		return i.advancedButtonFields.TransactionID, int(i.advancedButtonFields.Status)
+	case "cart":
+		return i.shoppingCartButtonFields.TransactionID, int(i.shoppingCartButtonFields.Status)
+	case "donation":
+		return i.donationButtonFields.TransactionID, int(i.donationButtonFields.Status)
+	case "deposit":
+		return i.depositInformation.TransactionID, int(i.depositInformation.Status)
+	case "withdrawal":
+		return i.withdrawalInformation.ID, int(i.withdrawalInformation.Status)
+	case "api":
+		return i.apiGeneratedTransactionFields.TransactionID, int(i.apiGeneratedTransactionFields.Status)
+	}
+	return "", 0
+}
@@ -0,0 +1,199 @@
+package coinpayments
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func testIPN(t *testing.T, values url.Values) *IPN {
+	t.Helper()
+
+	ipn, err := NewClient("public", "private").ParseIPN(newSignedIPNRequest(values), testIPNSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ipn
+}
+
+func testTransactionIPN(ipnType, ipnID, txnID, status string) url.Values {
+	values := testIPNValues(ipnType)
+	values.Set("ipn_id", ipnID)
+	values.Set("txn_id", txnID)
+	values.Set("status", status)
+	return values
+}
+
+type failingIPNStore struct{}
+
+func (failingIPNStore) Add(key string) (bool, error) { return false, errors.New("store down") }
+func (failingIPNStore) Remove(key string) error      { return nil }
+
+func TestDeduplicateIPN(t *testing.T) {
+	store := NewMemoryIPNStore(0)
+	var processed []string
+	fn := func(ipn *IPN) error {
+		processed = append(processed, ipn.IPNId)
+		return nil
+	}
+
+	first := testTransactionIPN("deposit", "1", "CP1", "0")
+	tests := []struct {
+		name      string
+		values    url.Values
+		duplicate bool
+	}{
+		{name: "first delivery", values: first},
+		{name: "same ipn id", values: first, duplicate: true},
+		{name: "same transaction and status with a new ipn id", values: testTransactionIPN("deposit", "2", "CP1", "0"), duplicate: true},
+		{name: "same transaction with a new status", values: testTransactionIPN("deposit", "3", "CP1", "100")},
+		{name: "same transaction id for another ipn type", values: testTransactionIPN("api", "4", "CP1", "100")},
+	}
+
+	for _, test := range tests {
+		duplicate, err := DeduplicateIPN(store, testIPN(t, test.values), fn)
+		if err != nil {
+			t.Fatalf("%v: %v", test.name, err)
+		}
+		if duplicate != test.duplicate {
+			t.Errorf("%v: duplicate = %v, want %v", test.name, duplicate, test.duplicate)
+		}
+	}
+
+	if want := []string{"1", "3", "4"}; len(processed) != len(want) || processed[0] != "1" || processed[1] != "3" || processed[2] != "4" {
+		t.Errorf("processed %q, want %q", processed, want)
+	}
+}
+
+func TestDeduplicateIPNForgetsFailures(t *testing.T) {
+	store := NewMemoryIPNStore(0)
+	ipn := testIPN(t, testTransactionIPN("deposit", "1", "CP1", "100"))
+
+	calls := 0
+	fail := func(ipn *IPN) error {
+		calls++
+		return errors.New("database down")
+	}
+	if _, err := DeduplicateIPN(store, ipn, fail); err == nil {
+		t.Fatal("expected the callback error")
+	}
+
+	duplicate, err := DeduplicateIPN(store, ipn, func(ipn *IPN) error {
+		calls++
+		return nil
+	})
+	if err != nil || duplicate {
+		t.Errorf("resend after a failure = %v, %v, want it processed", duplicate, err)
+	}
+	if calls != 2 {
+		t.Errorf("callback called %v times, want 2", calls)
+	}
+}
+
+func TestDeduplicateIPNInFlight(t *testing.T) {
+	store := NewMemoryIPNStore(0)
+	ipn := testIPN(t, testTransactionIPN("deposit", "1", "CP1", "100"))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := DeduplicateIPN(store, ipn, func(ipn *IPN) error {
+			close(started)
+			<-release
+			return errors.New("database down")
+		})
+		done <- err
+	}()
+	<-started
+
+	called := false
+	duplicate, err := DeduplicateIPN(store, ipn, func(ipn *IPN) error {
+		called = true
+		return nil
+	})
+	if _, ok := err.(*IPNInFlightError); !ok || duplicate || called {
+		t.Errorf("resend while in flight = %v, %v with callback called %v, want an *IPNInFlightError and no call", duplicate, err, called)
+	}
+
+	close(release)
+	if err := <-done; err == nil {
+		t.Fatal("expected the first delivery to fail")
+	}
+
+	duplicate, err = DeduplicateIPN(store, ipn, func(ipn *IPN) error {
+		called = true
+		return nil
+	})
+	if err != nil || duplicate || !called {
+		t.Errorf("resend after the failure = %v, %v with callback called %v, want it processed", duplicate, err, called)
+	}
+}
+
+func TestDeduplicateIPNStoreError(t *testing.T) {
+	called := false
+	_, err := DeduplicateIPN(failingIPNStore{}, testIPN(t, testIPNValues("deposit")), func(ipn *IPN) error {
+		called = true
+		return nil
+	})
+	if err == nil || called {
+		t.Errorf("got error %v with callback called %v, want an error and no call", err, called)
+	}
+}
+
+func TestIPNHandlerDeduplicate(t *testing.T) {
+	handler := NewIPNHandler(NewClient("public", "private"), testIPNSecret)
+	handler.Deduplicate(NewMemoryIPNStore(0))
+
+	calls := 0
+	handler.OnDeposit(func(ipn *DepositIPN) error {
+		calls++
+		return nil
+	})
+
+	values := testTransactionIPN("deposit", "1", "CP1", "100")
+	for i := 0; i < 3; i++ {
+		if w := serveIPN(handler, newSignedIPNRequest(values)); w.Code != http.StatusOK {
+			t.Errorf("delivery %v: got %v, want 200", i, w.Code)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("callback called %v times, want 1", calls)
+	}
+}
+
+func TestIPNHandlerDeduplicateInFlight(t *testing.T) {
+	handler := NewIPNHandler(NewClient("public", "private"), testIPNSecret)
+	handler.Deduplicate(NewMemoryIPNStore(0))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	calls := 0
+	handler.OnDeposit(func(ipn *DepositIPN) error {
+		calls++
+		if calls == 1 {
+			close(started)
+			<-release
+		}
+		return nil
+	})
+
+	values := testTransactionIPN("deposit", "1", "CP1", "100")
+	first := make(chan int)
+	go func() {
+		first <- serveIPN(handler, newSignedIPNRequest(values)).Code
+	}()
+	<-started
+
+	if w := serveIPN(handler, newSignedIPNRequest(values)); w.Code != http.StatusInternalServerError {
+		t.Errorf("resend while in flight: got %v, want 500", w.Code)
+	}
+	close(release)
+	if code := <-first; code != http.StatusOK {
+		t.Errorf("first delivery: got %v, want 200", code)
+	}
+	if w := serveIPN(handler, newSignedIPNRequest(values)); w.Code != http.StatusOK || calls != 1 {
+		t.Errorf("resend after success: got %v with %v calls, want 200 and one call", w.Code, calls)
+	}
+}
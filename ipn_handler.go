@@ -0,0 +1,214 @@
+package coinpayments
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const ipnOK = "IPN OK"
+
+//UnhandledIPNError is returned when no callback is registered for an IPN's type
+type UnhandledIPNError struct {
+	IPNType string
+}
+
+func (e *UnhandledIPNError) Error() string {
+	return fmt.Sprintf("coinpayments: no handler registered for ipn type %q", e.IPNType)
+}
+
+//IPNHandler is an http.Handler that verifies IPNs and dispatches them to the registered callbacks
+type IPNHandler struct {
+	client    *Client
+	ipnSecret string
+	options   []IPNOption
+	store     IPNStore
+	reconcile func(ipn *IPN) bool
+
+	onAPI        func(ipn *ApiIPN) error
+	onDeposit    func(ipn *DepositIPN) error
+	onWithdrawal func(ipn *WithdrawalIPN) error
+	onSimple     func(ipn *SimpleIPN) error
+	onButton     func(ipn *ButtonIPN) error
+	onCart       func(ipn *CartIPN) error
+	onDonation   func(ipn *DonationIPN) error
+	onUnhandled  func(ipn *IPN) error
+	onError      func(r *http.Request, err error)
+}
+
+//NewIPNHandler returns a new IPNHandler that verifies IPNs with the provided secret and options
+func NewIPNHandler(client *Client, ipnSecret string, options ...IPNOption) *IPNHandler {
+	return &IPNHandler{
+		client:    client,
+		ipnSecret: ipnSecret,
+		options:   options,
+	}
+}
+
+//OnAPI registers the callback for 'api' IPNs
+func (h *IPNHandler) OnAPI(fn func(ipn *ApiIPN) error) {
+	h.onAPI = fn
+}
+
+//OnDeposit registers the callback for 'deposit' IPNs
+func (h *IPNHandler) OnDeposit(fn func(ipn *DepositIPN) error) {
+	h.onDeposit = fn
+}
+
+//OnWithdrawal registers the callback for 'withdrawal' IPNs
+func (h *IPNHandler) OnWithdrawal(fn func(ipn *WithdrawalIPN) error) {
+	h.onWithdrawal = fn
+}
+
+//OnSimple registers the callback for 'simple' IPNs
+func (h *IPNHandler) OnSimple(fn func(ipn *SimpleIPN) error) {
+	h.onSimple = fn
+}
+
+//OnButton registers the callback for 'button' IPNs
+func (h *IPNHandler) OnButton(fn func(ipn *ButtonIPN) error) {
+	h.onButton = fn
+}
+
+//OnCart registers the callback for 'cart' IPNs
+func (h *IPNHandler) OnCart(fn func(ipn *CartIPN) error) {
+	h.onCart = fn
+}
+
+//OnDonation registers the callback for 'donation' IPNs
+func (h *IPNHandler) OnDonation(fn func(ipn *DonationIPN) error) {
+	h.onDonation = fn
+}
+
+//OnUnhandled registers the callback for IPNs whose type has no other callback registered
+func (h *IPNHandler) OnUnhandled(fn func(ipn *IPN) error) {
+	h.onUnhandled = fn
+}
+
+//OnError registers a callback that is told about every IPN that could not be verified or handled. The request is
+//nil for errors from background processing
+func (h *IPNHandler) OnError(fn func(r *http.Request, err error)) {
+	h.onError = fn
+}
+
+//Deduplicate makes the handler acknowledge IPNs already recorded in the store without dispatching them again
+func (h *IPNHandler) Deduplicate(store IPNStore) {
+	h.store = store
+}
+
+//Reconcile makes the handler confirm IPNs with ReconcileIPN before dispatching them. Only IPNs the filter accepts
+//are confirmed, or every IPN if the filter is nil
+func (h *IPNHandler) Reconcile(filter func(ipn *IPN) bool) {
+	if filter == nil {
+		filter = func(ipn *IPN) bool { return true }
+	}
+	h.reconcile = filter
+}
+
+//Dispatch calls the callback registered for the IPN's type. An UnhandledIPNError is returned if there is none
+func (h *IPNHandler) Dispatch(ipn *IPN) error {
+	switch {
+	case ipn.IPNType == "api" && h.onAPI != nil:
+		typed, err := ipn.ToApiIPN()
+		if err != nil {
+			return err
+		}
+		return h.onAPI(typed)
+	case ipn.IPNType == "deposit" && h.onDeposit != nil:
+		typed, err := ipn.ToDepositIPN()
+		if err != nil {
+			return err
+		}
+		return h.onDeposit(typed)
+	case ipn.IPNType == "withdrawal" && h.onWithdrawal != nil:
+		typed, err := ipn.ToWithdrawalIPN()
+		if err != nil {
+			return err
+		}
+		return h.onWithdrawal(typed)
+	case ipn.IPNType == "simple" && h.onSimple != nil:
+		typed, err := ipn.ToSimpleIPN()
+		if err != nil {
+			return err
+		}
+		return h.onSimple(typed)
+	case ipn.IPNType == "button" && h.onButton != nil:
+		typed, err := ipn.ToButtonIPN()
+		if err != nil {
+			return err
+		}
+		return h.onButton(typed)
+	case ipn.IPNType == "cart" && h.onCart != nil:
+		typed, err := ipn.ToCartIPN()
+		if err != nil {
+			return err
+		}
+		return h.onCart(typed)
+	case ipn.IPNType == "donation" && h.onDonation != nil:
+		typed, err := ipn.ToDonationIPN()
+		if err != nil {
+			return err
+		}
+		return h.onDonation(typed)
+	case h.onUnhandled != nil:
+		return h.onUnhandled(ipn)
+	}
+
+	return &UnhandledIPNError{IPNType: ipn.IPNType}
+}
+
+//ServeHTTP verifies the IPN and dispatches it. A 200 'IPN OK' response is only sent once the callback succeeds,
+//so that coinpayments resends the IPN after any failure
+func (h *IPNHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	ipn, err := h.client.ParseIPN(r, h.ipnSecret, h.options...)
+	if err != nil {
+		h.reportError(r, err)
+		http.Error(w, "IPN verification failed", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.handle(ipn); err != nil {
+		h.reportError(r, err)
+		if _, ok := err.(*UnhandledIPNError); ok {
+			http.Error(w, "IPN type not handled", http.StatusNotImplemented)
+			return
+		}
+		http.Error(w, "IPN handling failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, ipnOK)
+}
+
+func (h *IPNHandler) handle(ipn *IPN) error {
+	if h.store == nil {
+		return h.process(ipn)
+	}
+
+	_, err := DeduplicateIPN(h.store, ipn, h.process)
+	return err
+}
+
+func (h *IPNHandler) process(ipn *IPN) error {
+	if h.reconcile != nil && h.reconcile(ipn) {
+		if err := h.client.ReconcileIPN(ipn); err != nil {
+			return err
+		}
+	}
+
+	return h.Dispatch(ipn)
+}
+
+func (h *IPNHandler) reportError(r *http.Request, err error) {
+	if h.onError != nil {
+		h.onError(r, err)
+	}
+}
@@ -0,0 +1,121 @@
+package coinpayments
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveIPN(handler http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestIPNHandlerDispatch(t *testing.T) {
+	handler := NewIPNHandler(NewClient("public", "private"), testIPNSecret)
+
+	var got []string
+	handler.OnAPI(func(ipn *ApiIPN) error { got = append(got, "api "+ipn.TransactionID); return nil })
+	handler.OnDeposit(func(ipn *DepositIPN) error { got = append(got, "deposit "+ipn.Address); return nil })
+	handler.OnWithdrawal(func(ipn *WithdrawalIPN) error { got = append(got, "withdrawal "+ipn.Address); return nil })
+	handler.OnSimple(func(ipn *SimpleIPN) error { got = append(got, "simple "+ipn.ItemName); return nil })
+	handler.OnButton(func(ipn *ButtonIPN) error { got = append(got, "button "+ipn.ItemName); return nil })
+	handler.OnCart(func(ipn *CartIPN) error { got = append(got, "cart "+ipn.Invoice); return nil })
+	handler.OnDonation(func(ipn *DonationIPN) error { got = append(got, "donation "+ipn.ItemName); return nil })
+
+	fields := []struct {
+		ipnType string
+		key     string
+		value   string
+	}{
+		{ipnType: "api", key: "txn_id", value: "CPAPI"},
+		{ipnType: "deposit", key: "address", value: "depositAddress"},
+		{ipnType: "withdrawal", key: "address", value: "withdrawalAddress"},
+		{ipnType: "simple", key: "item_name", value: "Simple Item"},
+		{ipnType: "button", key: "item_name", value: "Button Item"},
+		{ipnType: "cart", key: "invoice", value: "INV-1"},
+		{ipnType: "donation", key: "item_name", value: "Donation"},
+	}
+	for _, field := range fields {
+		values := testIPNValues(field.ipnType)
+		values.Set(field.key, field.value)
+		w := serveIPN(handler, newSignedIPNRequest(values))
+		if w.Code != http.StatusOK || w.Body.String() != ipnOK {
+			t.Errorf("%v: got %v %q, want 200 %q", field.ipnType, w.Code, w.Body.String(), ipnOK)
+		}
+	}
+
+	want := []string{
+		"api CPAPI",
+		"deposit depositAddress",
+		"withdrawal withdrawalAddress",
+		"simple Simple Item",
+		"button Button Item",
+		"cart INV-1",
+		"donation Donation",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("dispatched %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("dispatch %v = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestIPNHandlerResponses(t *testing.T) {
+	var reported []error
+	handler := NewIPNHandler(NewClient("public", "private"), testIPNSecret)
+	handler.OnDeposit(func(ipn *DepositIPN) error { return errors.New("database down") })
+	handler.OnError(func(r *http.Request, err error) { reported = append(reported, err) })
+
+	r := newSignedIPNRequest(testIPNValues("deposit"))
+	r.Method = http.MethodGet
+	if w := serveIPN(handler, r); w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("GET: got %v with Allow %q, want 405 with Allow POST", w.Code, w.Header().Get("Allow"))
+	}
+
+	r = newSignedIPNRequest(testIPNValues("deposit"))
+	r.Header.Set("HMAC", "bad")
+	if w := serveIPN(handler, r); w.Code != http.StatusBadRequest {
+		t.Errorf("bad hmac: got %v, want 400", w.Code)
+	}
+
+	if w := serveIPN(handler, newSignedIPNRequest(testIPNValues("deposit"))); w.Code != http.StatusInternalServerError {
+		t.Errorf("failing callback: got %v, want 500", w.Code)
+	}
+
+	if w := serveIPN(handler, newSignedIPNRequest(testIPNValues("withdrawal"))); w.Code != http.StatusNotImplemented {
+		t.Errorf("unhandled type: got %v, want 501", w.Code)
+	}
+
+	if len(reported) != 3 {
+		t.Fatalf("reported %v errors, want 3", len(reported))
+	}
+	if _, ok := reported[2].(*UnhandledIPNError); !ok {
+		t.Errorf("got error %v, want *UnhandledIPNError", reported[2])
+	}
+}
+
+func TestIPNHandlerUnhandledFallback(t *testing.T) {
+	handler := NewIPNHandler(NewClient("public", "private"), testIPNSecret)
+
+	var unhandled []string
+	handler.OnUnhandled(func(ipn *IPN) error {
+		unhandled = append(unhandled, ipn.IPNType)
+		return nil
+	})
+	handler.OnDeposit(func(ipn *DepositIPN) error { return nil })
+
+	for _, ipnType := range []string{"deposit", "withdrawal", "api"} {
+		if w := serveIPN(handler, newSignedIPNRequest(testIPNValues(ipnType))); w.Code != http.StatusOK {
+			t.Errorf("%v: got %v, want 200", ipnType, w.Code)
+		}
+	}
+	if len(unhandled) != 2 || unhandled[0] != "withdrawal" || unhandled[1] != "api" {
+		t.Errorf("unhandled callback saw %q, want [withdrawal api]", unhandled)
+	}
+}
@@ -0,0 +1,107 @@
+package coinpayments
+
+import "fmt"
+
+//PaymentVerdict is the outcome of checking an api IPN against the order it should pay for
+type PaymentVerdict string
+
+const (
+	PaymentPaid          PaymentVerdict = "paid"
+	PaymentUnderpaid     PaymentVerdict = "underpaid"
+	PaymentOverpaid      PaymentVerdict = "overpaid"
+	PaymentWrongCurrency PaymentVerdict = "wrong_currency"
+	PaymentWrongAmount   PaymentVerdict = "wrong_amount"
+	PaymentPending       PaymentVerdict = "pending"
+	PaymentCancelled     PaymentVerdict = "cancelled"
+)
+
+//ExpectedOrder is the order a transaction was created for, as recorded by the merchant
+type ExpectedOrder struct {
+	TransactionID string
+	Currency1     Currency
+	Amount1       Amount
+	Invoice       string
+	Custom        string
+}
+
+//PaymentResult describes how an api IPN compares to the order it should pay for
+type PaymentResult struct {
+	Verdict PaymentVerdict
+	Status  PaymentStatus
+
+	ExpectedCurrency Currency
+	Currency         Currency
+	ExpectedAmount   Amount
+	Amount           Amount
+
+	Currency2      Currency
+	Amount2        Amount
+	ReceivedAmount Amount
+	Shortfall      Amount
+}
+
+//VerifyPayment checks an api IPN against the order it should pay for. An error is returned if the IPN does not
+//belong to the order, otherwise the result holds the verdict and the amounts involved. An IPN whose amount1 differs
+//from the order is PaymentWrongAmount, as that is the price of the transaction rather than what was paid. Whether the
+//buyer underpaid or overpaid is decided by received_amount against amount2 for any status that has not failed, so a
+//pending IPN that has only been partly paid is PaymentUnderpaid. The Shortfall holds what is left to pay in currency2
+func VerifyPayment(order *ExpectedOrder, ipn *ApiIPN) (*PaymentResult, error) {
+	if ipn.TransactionID != order.TransactionID {
+		return nil, fmt.Errorf("coinpayments: ipn txn_id %q does not match order %q", ipn.TransactionID, order.TransactionID)
+	}
+	if ipn.Invoice != order.Invoice {
+		return nil, fmt.Errorf("coinpayments: ipn invoice %q does not match order %q", ipn.Invoice, order.Invoice)
+	}
+	if ipn.Custom != order.Custom {
+		return nil, fmt.Errorf("coinpayments: ipn custom %q does not match order %q", ipn.Custom, order.Custom)
+	}
+
+	result := &PaymentResult{
+		Status:           ipn.Status,
+		ExpectedCurrency: order.Currency1,
+		Currency:         ipn.Currency1,
+		ExpectedAmount:   order.Amount1,
+		Amount:           ipn.Amount1,
+		Currency2:        ipn.Currency2,
+		Amount2:          ipn.Amount2,
+		ReceivedAmount:   ipn.ReceivedAmount,
+	}
+
+	if !ipn.ReceivedAmount.IsZero() && ipn.ReceivedAmount.LessThan(ipn.Amount2) {
+		result.Shortfall = ipn.Amount2.Sub(ipn.ReceivedAmount)
+	}
+
+	if ipn.Status.IsFailed() {
+		result.Verdict = PaymentCancelled
+		return result, nil
+	}
+
+	if !ipn.Currency1.Equal(order.Currency1) {
+		result.Verdict = PaymentWrongCurrency
+		return result, nil
+	}
+
+	if !ipn.Amount1.Equal(order.Amount1) {
+		result.Verdict = PaymentWrongAmount
+		return result, nil
+	}
+
+	if !ipn.ReceivedAmount.IsZero() {
+		switch ipn.ReceivedAmount.Cmp(ipn.Amount2) {
+		case -1:
+			result.Verdict = PaymentUnderpaid
+			return result, nil
+		case 1:
+			result.Verdict = PaymentOverpaid
+			return result, nil
+		}
+	}
+
+	if !ipn.Status.IsComplete() {
+		result.Verdict = PaymentPending
+		return result, nil
+	}
+
+	result.Verdict = PaymentPaid
+	return result, nil
+}
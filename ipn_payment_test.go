@@ -0,0 +1,86 @@
+package coinpayments
+
+import "testing"
+
+func testApiIPN(t *testing.T, builder *IPNBuilder) *ApiIPN {
+	t.Helper()
+
+	ipn, err := testIPN(t, builder.Values()).ToApiIPN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ipn
+}
+
+func TestVerifyPayment(t *testing.T) {
+	order := &ExpectedOrder{
+		TransactionID: "CP1",
+		Currency1:     "USD",
+		Amount1:       MustParseAmount("10"),
+		Invoice:       "INV-1",
+		Custom:        "user-7",
+	}
+	ipn := func() *IPNBuilder {
+		return NewIPNBuilder("api").Set("txn_id", "CP1").Set("invoice", "INV-1").Set("custom", "user-7")
+	}
+
+	tests := []struct {
+		name      string
+		builder   *IPNBuilder
+		want      PaymentVerdict
+		shortfall string
+	}{
+		{name: "paid", builder: ipn(), want: PaymentPaid},
+		{name: "paid with currency in lower case", builder: ipn().Set("currency1", "usd"), want: PaymentPaid},
+		{name: "paid in escrow", builder: ipn().Set("status", "2"), want: PaymentPaid},
+		{name: "paid without a received amount", builder: ipn().Del("received_amount"), want: PaymentPaid},
+		{name: "pending", builder: ipn().Set("status", "1"), want: PaymentPending},
+		{name: "cancelled", builder: ipn().Set("status", "-1"), want: PaymentCancelled},
+		{name: "refunded", builder: ipn().Set("status", "-2"), want: PaymentCancelled},
+		{name: "wrong currency", builder: ipn().Set("currency1", "EUR"), want: PaymentWrongCurrency},
+		{name: "lower order amount", builder: ipn().Set("amount1", "9.99"), want: PaymentWrongAmount},
+		{name: "higher order amount", builder: ipn().Set("amount1", "10.01"), want: PaymentWrongAmount},
+		{name: "underpaid coin amount", builder: ipn().Set("received_amount", "0.00099999"), want: PaymentUnderpaid, shortfall: "0.00000001"},
+		{name: "overpaid coin amount", builder: ipn().Set("received_amount", "0.00100001"), want: PaymentOverpaid},
+		{name: "partly paid while pending", builder: ipn().Set("status", "1").Set("received_amount", "0.0004"), want: PaymentUnderpaid, shortfall: "0.0006"},
+		{name: "overpaid while pending", builder: ipn().Set("status", "1").Set("received_amount", "0.002"), want: PaymentOverpaid},
+		{name: "cancelled after a partial payment", builder: ipn().Set("status", "-1").Set("received_amount", "0.0004"), want: PaymentCancelled, shortfall: "0.0006"},
+		{name: "cancelled takes precedence over amounts", builder: ipn().Set("status", "-1").Set("amount1", "1"), want: PaymentCancelled},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := VerifyPayment(order, testApiIPN(t, test.builder))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Verdict != test.want {
+				t.Errorf("Verdict = %v, want %v", result.Verdict, test.want)
+			}
+			var shortfall Amount
+			if test.shortfall != "" {
+				shortfall = MustParseAmount(test.shortfall)
+			}
+			if !result.Shortfall.Equal(shortfall) {
+				t.Errorf("Shortfall = %v, want %v", result.Shortfall, shortfall)
+			}
+			if !result.ExpectedAmount.Equal(order.Amount1) || result.ExpectedCurrency != order.Currency1 {
+				t.Errorf("unexpected result %+v", result)
+			}
+		})
+	}
+}
+
+func TestVerifyPaymentOtherOrder(t *testing.T) {
+	order := &ExpectedOrder{TransactionID: "CP1", Currency1: "USD", Amount1: MustParseAmount("10"), Invoice: "INV-1"}
+
+	for name, builder := range map[string]*IPNBuilder{
+		"txn_id":  NewIPNBuilder("api").Set("txn_id", "CP2").Set("invoice", "INV-1"),
+		"invoice": NewIPNBuilder("api").Set("txn_id", "CP1").Set("invoice", "INV-2"),
+		"custom":  NewIPNBuilder("api").Set("txn_id", "CP1").Set("invoice", "INV-1").Set("custom", "x"),
+	} {
+		if _, err := VerifyPayment(order, testApiIPN(t, builder)); err == nil {
+			t.Errorf("expected an error for a mismatched %v", name)
+		}
+	}
+}
@@ -0,0 +1,424 @@
+package coinpayments
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+//IPNPipelineOption is an option used to modify an IPNPipeline
+type IPNPipelineOption func(pipeline *IPNPipeline)
+
+const (
+	defaultIPNWorkers     = 4
+	defaultIPNMaxAttempts = 8
+	defaultIPNMinBackoff  = time.Second
+	defaultIPNMaxBackoff  = 10 * time.Minute
+	defaultIPNSeenTTL     = 7 * 24 * time.Hour
+)
+
+//WithWorkers is an option that sets how many IPNs the pipeline processes at once. Values below 1 keep the default
+//of 4
+func WithWorkers(workers int) IPNPipelineOption {
+	return func(pipeline *IPNPipeline) {
+		if workers < 1 {
+			workers = defaultIPNWorkers
+		}
+		pipeline.workers = workers
+	}
+}
+
+//WithMaxAttempts is an option that sets how many times an IPN is tried before it is dead-lettered. Values below 1
+//keep the default of 8
+func WithMaxAttempts(attempts int) IPNPipelineOption {
+	return func(pipeline *IPNPipeline) {
+		if attempts < 1 {
+			attempts = defaultIPNMaxAttempts
+		}
+		pipeline.maxAttempts = attempts
+	}
+}
+
+//WithBackoff is an option that sets the delay before the first retry, doubling up to max for each later retry. A
+//min below zero is treated as zero and a max below min as min
+func WithBackoff(min, max time.Duration) IPNPipelineOption {
+	return func(pipeline *IPNPipeline) {
+		if min < 0 {
+			min = 0
+		}
+		if max < min {
+			max = min
+		}
+		pipeline.minBackoff = min
+		pipeline.maxBackoff = max
+	}
+}
+
+//WithSeenStore is an option that sets where the pipeline records the ids of the IPNs it has queued, so that a
+//redelivery of one that was already processed is not queued again. The default is a MemoryIPNStore that keeps ids
+//for 7 days, a FileIPNStore keeps them across restarts. It should not be the store given to IPNHandler.Deduplicate
+func WithSeenStore(store IPNStore) IPNPipelineOption {
+	return func(pipeline *IPNPipeline) {
+		if store != nil {
+			pipeline.seen = store
+		}
+	}
+}
+
+//IPNPipeline is an http.Handler that verifies and enqueues IPNs, acknowledging them immediately, and processes them
+//in the background with the callbacks of an IPNHandler. Failed IPNs are retried with exponential backoff and moved to
+//a dead-letter store once they run out of attempts
+type IPNPipeline struct {
+	handler     *IPNHandler
+	queue       IPNJobStore
+	deadLetters IPNJobStore
+	seen        IPNStore
+
+	workers     int
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	ready   []*IPNJob
+	timers  map[string]*time.Timer
+	pending map[string]bool
+	running bool
+	wg      sync.WaitGroup
+}
+
+//NewIPNPipeline returns a new IPNPipeline that holds pending IPNs in queue and failed IPNs in deadLetters
+func NewIPNPipeline(handler *IPNHandler, queue, deadLetters IPNJobStore, options ...IPNPipelineOption) *IPNPipeline {
+	pipeline := &IPNPipeline{
+		handler:     handler,
+		queue:       queue,
+		deadLetters: deadLetters,
+		seen:        NewMemoryIPNStore(defaultIPNSeenTTL),
+		workers:     defaultIPNWorkers,
+		maxAttempts: defaultIPNMaxAttempts,
+		minBackoff:  defaultIPNMinBackoff,
+		maxBackoff:  defaultIPNMaxBackoff,
+		timers:      make(map[string]*time.Timer),
+		pending:     make(map[string]bool),
+	}
+	pipeline.cond = sync.NewCond(&pipeline.mu)
+
+	for _, o := range options {
+		o(pipeline)
+	}
+	return pipeline
+}
+
+//Start recovers the IPNs left in the queue and starts the workers
+func (p *IPNPipeline) Start() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running {
+		return fmt.Errorf("coinpayments: ipn pipeline already started")
+	}
+
+	jobs, err := p.queue.List()
+	if err != nil {
+		return err
+	}
+
+	p.running = true
+	p.ready = p.ready[:0]
+	for _, job := range jobs {
+		if p.pending[job.ID] {
+			continue
+		}
+		p.pending[job.ID] = true
+		p.ready = append(p.ready, job)
+	}
+
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return nil
+}
+
+//Stop stops the workers once they finish their current IPN. Queued IPNs stay in the queue for the next Start
+func (p *IPNPipeline) Stop() {
+	p.mu.Lock()
+	p.running = false
+	p.ready = nil
+	for id, timer := range p.timers {
+		timer.Stop()
+		delete(p.timers, id)
+	}
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	p.wg.Wait()
+
+	p.mu.Lock()
+	if !p.running {
+		p.pending = make(map[string]bool)
+	}
+	p.mu.Unlock()
+}
+
+//ServeHTTP verifies the IPN and durably enqueues it before responding with 200 'IPN OK'
+func (p *IPNPipeline) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	ipn, err := p.handler.client.ParseIPN(r, p.handler.ipnSecret, p.handler.options...)
+	if err != nil {
+		p.handler.reportError(r, err)
+		http.Error(w, "IPN verification failed", http.StatusBadRequest)
+		return
+	}
+
+	if err := p.Enqueue(ipn); err != nil {
+		p.handler.reportError(r, err)
+		http.Error(w, "IPN could not be queued", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, ipnOK)
+}
+
+//Enqueue durably queues a verified IPN for processing. An IPN whose id is already queued, being processed, waiting
+//to be retried, processed or dead-lettered is left as it is, so a redelivered IPN is not processed twice or has its
+//attempts reset. Dead-lettered IPNs are queued again with Replay. The IPN must come from ParseIPN or ParseIPNBytes,
+//as its raw values are what is queued
+func (p *IPNPipeline) Enqueue(ipn *IPN) error {
+	if ipn.values == nil {
+		return fmt.Errorf("coinpayments: ipn has no raw values to queue, it must be parsed with ParseIPN or ParseIPNBytes")
+	}
+
+	id := ipn.IPNId
+	if id == "" {
+		var err error
+		if id, err = randomID(); err != nil {
+			return err
+		}
+	}
+
+	job := &IPNJob{
+		ID:         id,
+		Data:       ipn.values.Encode(),
+		EnqueuedAt: time.Now(),
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pending[id] {
+		return nil
+	}
+	if !p.running {
+		queued, err := p.queue.Get(id)
+		if err != nil {
+			return fmt.Errorf("coinpayments: error queueing ipn - %v", err)
+		}
+		if queued != nil {
+			return nil
+		}
+	}
+	deadLettered, err := p.deadLetters.Get(id)
+	if err != nil {
+		return fmt.Errorf("coinpayments: error queueing ipn - %v", err)
+	}
+	if deadLettered != nil {
+		return nil
+	}
+
+	added, err := p.seen.Add("queued:" + id)
+	if err != nil {
+		return fmt.Errorf("coinpayments: error queueing ipn - %v", err)
+	}
+	if !added {
+		return nil
+	}
+	if err := p.queue.Save(job); err != nil {
+		_ = p.seen.Remove("queued:" + id)
+		return fmt.Errorf("coinpayments: error queueing ipn - %v", err)
+	}
+
+	p.push(job)
+	return nil
+}
+
+//DeadLetters returns the IPNs that ran out of attempts
+func (p *IPNPipeline) DeadLetters() ([]*IPNJob, error) {
+	return p.deadLetters.List()
+}
+
+//Replay moves a dead-lettered IPN back onto the queue with its attempts reset
+func (p *IPNPipeline) Replay(id string) error {
+	job, err := p.deadLetters.Get(id)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return fmt.Errorf("coinpayments: no dead-lettered ipn with id %q", id)
+	}
+
+	job.Attempts = 0
+	job.LastError = ""
+	job.FailedAt = time.Time{}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pending[id] {
+		return fmt.Errorf("coinpayments: ipn %q is already queued", id)
+	}
+	if err := p.queue.Save(job); err != nil {
+		return err
+	}
+	if err := p.deadLetters.Delete(id); err != nil {
+		return err
+	}
+
+	p.push(job)
+	return nil
+}
+
+//ReplayAll moves every dead-lettered IPN back onto the queue
+func (p *IPNPipeline) ReplayAll() error {
+	jobs, err := p.deadLetters.List()
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if err := p.Replay(job.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//push hands a queued job to the workers, marking its id as pending. p.mu must be held
+func (p *IPNPipeline) push(job *IPNJob) {
+	if !p.running {
+		return
+	}
+	p.pending[job.ID] = true
+	p.ready = append(p.ready, job)
+	p.cond.Signal()
+}
+
+//finish clears the pending mark of a job that succeeded or was dead-lettered
+func (p *IPNPipeline) finish(job *IPNJob) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.pending, job.ID)
+}
+
+func (p *IPNPipeline) work() {
+	defer p.wg.Done()
+
+	for {
+		p.mu.Lock()
+		for p.running && len(p.ready) == 0 {
+			p.cond.Wait()
+		}
+		if !p.running {
+			p.mu.Unlock()
+			return
+		}
+		job := p.ready[0]
+		p.ready = p.ready[1:]
+		p.mu.Unlock()
+
+		p.process(job)
+	}
+}
+
+func (p *IPNPipeline) process(job *IPNJob) {
+	values, err := url.ParseQuery(job.Data)
+	if err == nil {
+		var ipn *IPN
+		if ipn, err = parseIPNValues(values, newIPNConfig(p.handler.options).strict); err == nil {
+			err = p.handler.handle(ipn)
+		}
+	}
+
+	if err == nil {
+		if err := p.queue.Delete(job.ID); err != nil {
+			p.handler.reportError(nil, err)
+		}
+		p.finish(job)
+		return
+	}
+
+	job.Attempts++
+	job.LastError = err.Error()
+	p.handler.reportError(nil, fmt.Errorf("coinpayments: ipn %v failed attempt %v - %v", job.ID, job.Attempts, err))
+
+	if job.Attempts >= p.maxAttempts {
+		job.FailedAt = time.Now()
+		if err := p.deadLetters.Save(job); err != nil {
+			p.handler.reportError(nil, err)
+			p.finish(job)
+			return
+		}
+		if err := p.queue.Delete(job.ID); err != nil {
+			p.handler.reportError(nil, err)
+		}
+		p.finish(job)
+		return
+	}
+
+	if err := p.queue.Save(job); err != nil {
+		p.handler.reportError(nil, err)
+	}
+	p.retry(job)
+}
+
+func (p *IPNPipeline) retry(job *IPNJob) {
+	delay := p.minBackoff
+	for i := 1; i < job.Attempts && delay < p.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.maxBackoff {
+		delay = p.maxBackoff
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.running {
+		delete(p.pending, job.ID)
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		//a timer Stop could not cancel in time must not push a job the next Start recovered
+		if p.timers[job.ID] != timer {
+			return
+		}
+		delete(p.timers, job.ID)
+		p.push(job)
+	})
+	p.timers[job.ID] = timer
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("coinpayments: error generating id - %v", err)
+	}
+	return fmt.Sprintf("%x", b), nil
+}
@@ -0,0 +1,297 @@
+package coinpayments
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %v", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func testDepositIPN(t *testing.T) *IPN {
+	t.Helper()
+
+	return testIPN(t, testTransactionIPN("deposit", "IPN1", "CP1", "100"))
+}
+
+func countJobs(t *testing.T, store IPNJobStore) int {
+	t.Helper()
+
+	jobs, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(jobs)
+}
+
+func TestIPNPipelineRetriesThenSucceeds(t *testing.T) {
+	var calls int32
+	handler := NewIPNHandler(nil, "")
+	handler.OnDeposit(func(ipn *DepositIPN) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+
+	queue, deadLetters := NewMemoryIPNJobStore(), NewMemoryIPNJobStore()
+	pipeline := NewIPNPipeline(handler, queue, deadLetters, WithBackoff(time.Millisecond, 2*time.Millisecond))
+	if err := pipeline.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pipeline.Stop()
+
+	if err := pipeline.Enqueue(testDepositIPN(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, "the ipn to succeed", func() bool { return countJobs(t, queue) == 0 })
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("handler called %v times, want 3", got)
+	}
+	if got := countJobs(t, deadLetters); got != 0 {
+		t.Errorf("%v dead letters, want 0", got)
+	}
+}
+
+func TestIPNPipelineDeadLettersAndReplays(t *testing.T) {
+	var calls int32
+	var fail atomic.Value
+	fail.Store(true)
+
+	handler := NewIPNHandler(nil, "")
+	handler.OnDeposit(func(ipn *DepositIPN) error {
+		atomic.AddInt32(&calls, 1)
+		if fail.Load().(bool) {
+			return errors.New("permanent failure")
+		}
+		return nil
+	})
+
+	queue, deadLetters := NewMemoryIPNJobStore(), NewMemoryIPNJobStore()
+	pipeline := NewIPNPipeline(handler, queue, deadLetters, WithMaxAttempts(3), WithBackoff(time.Millisecond, time.Millisecond))
+	if err := pipeline.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pipeline.Stop()
+
+	ipn := testDepositIPN(t)
+	if err := pipeline.Enqueue(ipn); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, "the ipn to be dead-lettered", func() bool { return countJobs(t, deadLetters) == 1 })
+	jobs, err := pipeline.DeadLetters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job := jobs[0]; job.ID != ipn.IPNId || job.Attempts != 3 || job.LastError != "permanent failure" || job.FailedAt.IsZero() {
+		t.Errorf("unexpected dead letter %+v", job)
+	}
+	if got := countJobs(t, queue); got != 0 {
+		t.Errorf("%v jobs left in the queue, want 0", got)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("handler called %v times, want 3", got)
+	}
+
+	fail.Store(false)
+	if err := pipeline.ReplayAll(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the replayed ipn to succeed", func() bool { return atomic.LoadInt32(&calls) == 4 && countJobs(t, queue) == 0 })
+	if got := countJobs(t, deadLetters); got != 0 {
+		t.Errorf("%v dead letters after replay, want 0", got)
+	}
+	if err := pipeline.Replay(ipn.IPNId); err == nil {
+		t.Error("expected an error replaying an ipn that is no longer dead-lettered")
+	}
+}
+
+func TestIPNPipelineRecoversQueue(t *testing.T) {
+	var calls int32
+	handler := NewIPNHandler(nil, "")
+	handler.OnDeposit(func(ipn *DepositIPN) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+
+	queue := NewMemoryIPNJobStore()
+	pipeline := NewIPNPipeline(handler, queue, NewMemoryIPNJobStore())
+	if err := pipeline.Enqueue(testDepositIPN(t)); err != nil {
+		t.Fatal(err)
+	}
+	if got := countJobs(t, queue); got != 1 {
+		t.Fatalf("%v jobs queued before start, want 1", got)
+	}
+
+	if err := pipeline.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pipeline.Stop()
+
+	waitFor(t, "the queued ipn to succeed", func() bool { return countJobs(t, queue) == 0 })
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("handler called %v times, want 1", got)
+	}
+}
+
+func TestIPNPipelineEnqueueIgnoresQueuedIDs(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	handler := NewIPNHandler(nil, "")
+	handler.OnDeposit(func(ipn *DepositIPN) error {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return nil
+	})
+
+	queue := NewMemoryIPNJobStore()
+	pipeline := NewIPNPipeline(handler, queue, NewMemoryIPNJobStore())
+
+	ipn := testDepositIPN(t)
+	for i := 0; i < 2; i++ {
+		if err := pipeline.Enqueue(ipn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := countJobs(t, queue); got != 1 {
+		t.Fatalf("%v jobs queued before start, want 1", got)
+	}
+
+	if err := pipeline.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pipeline.Stop()
+
+	waitFor(t, "the ipn to be processed", func() bool { return atomic.LoadInt32(&calls) == 1 })
+	if err := pipeline.Enqueue(ipn); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+
+	waitFor(t, "the ipn to succeed", func() bool { return countJobs(t, queue) == 0 })
+	time.Sleep(10 * time.Millisecond)
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("handler called %v times, want 1", got)
+	}
+}
+
+func TestIPNPipelineEnqueueIgnoresFinishedIDs(t *testing.T) {
+	var calls int32
+	var fail atomic.Value
+	fail.Store(true)
+
+	handler := NewIPNHandler(nil, "")
+	handler.OnDeposit(func(ipn *DepositIPN) error {
+		atomic.AddInt32(&calls, 1)
+		if fail.Load().(bool) {
+			return errors.New("permanent failure")
+		}
+		return nil
+	})
+
+	queue, deadLetters := NewMemoryIPNJobStore(), NewMemoryIPNJobStore()
+	pipeline := NewIPNPipeline(handler, queue, deadLetters, WithMaxAttempts(1))
+	if err := pipeline.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pipeline.Stop()
+
+	failed := testIPN(t, testTransactionIPN("deposit", "IPN1", "CP1", "100"))
+	if err := pipeline.Enqueue(failed); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the ipn to be dead-lettered", func() bool { return countJobs(t, deadLetters) == 1 })
+	if err := pipeline.Enqueue(failed); err != nil {
+		t.Fatal(err)
+	}
+
+	fail.Store(false)
+	processed := testIPN(t, testTransactionIPN("deposit", "IPN2", "CP2", "100"))
+	if err := pipeline.Enqueue(processed); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the ipn to succeed", func() bool { return atomic.LoadInt32(&calls) == 2 && countJobs(t, queue) == 0 })
+	if err := pipeline.Enqueue(processed); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("handler called %v times, want 2", got)
+	}
+	if got := countJobs(t, queue); got != 0 {
+		t.Errorf("%v jobs queued, want 0", got)
+	}
+	jobs, err := pipeline.DeadLetters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].Attempts != 1 {
+		t.Errorf("dead letters = %+v, want the first ipn with its attempt kept", jobs)
+	}
+}
+
+func TestIPNPipelineEnqueueWithoutValues(t *testing.T) {
+	queue := NewMemoryIPNJobStore()
+	pipeline := NewIPNPipeline(NewIPNHandler(nil, ""), queue, NewMemoryIPNJobStore())
+
+	if err := pipeline.Enqueue(&IPN{ipnInformation: ipnInformation{IPNId: "IPN1", IPNType: "deposit"}}); err == nil {
+		t.Error("expected an error queueing an ipn that was not parsed")
+	}
+	if got := countJobs(t, queue); got != 0 {
+		t.Errorf("%v jobs queued, want 0", got)
+	}
+}
+
+func TestIPNPipelineStartTwice(t *testing.T) {
+	pipeline := NewIPNPipeline(NewIPNHandler(nil, ""), NewMemoryIPNJobStore(), NewMemoryIPNJobStore())
+	if err := pipeline.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer pipeline.Stop()
+
+	var wg sync.WaitGroup
+	var started int32
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if pipeline.Start() == nil {
+				atomic.AddInt32(&started, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if started != 0 {
+		t.Errorf("started %v more times, want 0", started)
+	}
+}
+
+func TestIPNPipelineOptionDefaults(t *testing.T) {
+	pipeline := NewIPNPipeline(NewIPNHandler(nil, ""), NewMemoryIPNJobStore(), NewMemoryIPNJobStore(),
+		WithWorkers(0), WithMaxAttempts(-1), WithBackoff(-time.Second, -time.Minute))
+
+	if pipeline.workers != defaultIPNWorkers {
+		t.Errorf("workers = %v, want %v", pipeline.workers, defaultIPNWorkers)
+	}
+	if pipeline.maxAttempts != defaultIPNMaxAttempts {
+		t.Errorf("maxAttempts = %v, want %v", pipeline.maxAttempts, defaultIPNMaxAttempts)
+	}
+	if pipeline.minBackoff != 0 || pipeline.maxBackoff != 0 {
+		t.Errorf("backoff = %v..%v, want 0..0", pipeline.minBackoff, pipeline.maxBackoff)
+	}
+}
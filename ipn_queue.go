@@ -0,0 +1,213 @@
+package coinpayments
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//IPNJob is a verified IPN waiting to be processed
+type IPNJob struct {
+	ID         string    `json:"id"`
+	Data       string    `json:"data"`
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"last_error,omitempty"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	FailedAt   time.Time `json:"failed_at,omitempty"`
+}
+
+//IPNJobStore persists IPN jobs, either while they are queued or once they have been dead-lettered
+type IPNJobStore interface {
+	//Save inserts the job or replaces the job with the same id
+	Save(job *IPNJob) error
+	//Get returns the job with the id, or nil if there is none
+	Get(id string) (*IPNJob, error)
+	//Delete removes the job with the id
+	Delete(id string) error
+	//List returns every job ordered by the time it was enqueued
+	List() ([]*IPNJob, error)
+}
+
+//MemoryIPNJobStore is an IPNJobStore that only holds jobs for the life of the process
+type MemoryIPNJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]IPNJob
+}
+
+//NewMemoryIPNJobStore returns a new, empty MemoryIPNJobStore
+func NewMemoryIPNJobStore() *MemoryIPNJobStore {
+	return &MemoryIPNJobStore{
+		jobs: make(map[string]IPNJob),
+	}
+}
+
+//Save inserts the job or replaces the job with the same id
+func (s *MemoryIPNJobStore) Save(job *IPNJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+//Get returns the job with the id, or nil if there is none
+func (s *MemoryIPNJobStore) Get(id string) (*IPNJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+//Delete removes the job with the id
+func (s *MemoryIPNJobStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.jobs, id)
+	return nil
+}
+
+//List returns every job ordered by the time it was enqueued
+func (s *MemoryIPNJobStore) List() ([]*IPNJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]*IPNJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		job := job
+		jobs = append(jobs, &job)
+	}
+	sortIPNJobs(jobs)
+	return jobs, nil
+}
+
+//DirIPNJobStore is an IPNJobStore that keeps each job as a json file in a directory
+type DirIPNJobStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+//NewDirIPNJobStore returns a new DirIPNJobStore, creating the directory if needed
+func NewDirIPNJobStore(dir string) (*DirIPNJobStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("coinpayments: error creating ipn job directory - %v", err)
+	}
+	return &DirIPNJobStore{dir: dir}, nil
+}
+
+//Save inserts the job or replaces the job with the same id
+func (s *DirIPNJobStore) Save(job *IPNJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("coinpayments: error marshaling ipn job - %v", err)
+	}
+
+	path := s.path(job.ID)
+	file, err := ioutil.TempFile(s.dir, ".tmp-")
+	if err != nil {
+		return fmt.Errorf("coinpayments: error writing ipn job - %v", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return fmt.Errorf("coinpayments: error writing ipn job - %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return fmt.Errorf("coinpayments: error syncing ipn job - %v", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("coinpayments: error writing ipn job - %v", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("coinpayments: error writing ipn job - %v", err)
+	}
+	return nil
+}
+
+//Get returns the job with the id, or nil if there is none
+func (s *DirIPNJobStore) Get(id string) (*IPNJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, err := readIPNJob(s.path(id))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return job, err
+}
+
+//Delete removes the job with the id
+func (s *DirIPNJobStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("coinpayments: error deleting ipn job - %v", err)
+	}
+	return nil
+}
+
+//List returns every job ordered by the time it was enqueued
+func (s *DirIPNJobStore) List() ([]*IPNJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	infos, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("coinpayments: error listing ipn jobs - %v", err)
+	}
+
+	var jobs []*IPNJob
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".json") {
+			continue
+		}
+		job, err := readIPNJob(filepath.Join(s.dir, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	sortIPNJobs(jobs)
+	return jobs, nil
+}
+
+func (s *DirIPNJobStore) path(id string) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%x.json", id))
+}
+
+func readIPNJob(path string) (*IPNJob, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &IPNJob{}
+	if err := json.Unmarshal(data, job); err != nil {
+		return nil, fmt.Errorf("coinpayments: error unmarshaling ipn job - %v", err)
+	}
+	return job, nil
+}
+
+func sortIPNJobs(jobs []*IPNJob) {
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].EnqueuedAt.Before(jobs[j].EnqueuedAt)
+	})
+}
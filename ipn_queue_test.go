@@ -0,0 +1,70 @@
+package coinpayments
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestIPNJobStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipnjobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dirStore, err := NewDirIPNJobStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]IPNJobStore{
+		"memory": NewMemoryIPNJobStore(),
+		"dir":    dirStore,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			start := time.Unix(1600000000, 0)
+			for i, id := range []string{"second", "first/../id", "third"} {
+				job := &IPNJob{ID: id, Data: "ipn_type=deposit", EnqueuedAt: start.Add(time.Duration(i) * time.Second)}
+				if id == "first/../id" {
+					job.EnqueuedAt = start.Add(-time.Second)
+				}
+				if err := store.Save(job); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			job, err := store.Get("second")
+			if err != nil {
+				t.Fatal(err)
+			}
+			job.Attempts = 2
+			job.LastError = "failed"
+			if err := store.Save(job); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Delete("third"); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Delete("missing"); err != nil {
+				t.Errorf("deleting a missing job: %v", err)
+			}
+
+			if job, err := store.Get("missing"); job != nil || err != nil {
+				t.Errorf("Get(missing) = %v, %v, want nil, nil", job, err)
+			}
+			jobs, err := store.List()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(jobs) != 2 || jobs[0].ID != "first/../id" || jobs[1].ID != "second" {
+				t.Fatalf("List = %+v, want first/../id then second", jobs)
+			}
+			if jobs[1].Attempts != 2 || jobs[1].LastError != "failed" || jobs[1].Data != "ipn_type=deposit" {
+				t.Errorf("saved job = %+v, want the updated job", jobs[1])
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package coinpayments
+
+import (
+	"fmt"
+	"strings"
+)
+
+//ReconciliationError is returned when an IPN disagrees with what get_tx_info reports for its transaction
+type ReconciliationError struct {
+	TransactionID string
+	Discrepancies []string
+}
+
+func (e *ReconciliationError) Error() string {
+	return fmt.Sprintf("coinpayments: ipn for %v does not match get_tx_info - %v", e.TransactionID, strings.Join(e.Discrepancies, "; "))
+}
+
+//ReconcileIPN confirms a payment IPN by looking its transaction up with GetTxInfo. A ReconciliationError is returned
+//if the IPN and the api put the transaction in different status classes (failed, pending or complete), report
+//different received amounts, or the coins differ. Deposit and withdrawal IPNs are not looked up
+func (c *Client) ReconcileIPN(ipn *IPN) error {
+	switch ipn.IPNType {
+	case "api", "simple", "button", "cart", "donation":
+	default:
+		return nil
+	}
+
+	txnID, ipnStatus := ipn.transaction()
+	coin, ipnReceived := ipn.received()
+
+	info, err := c.GetTxInfo(&GetTxInfoRequest{TXID: txnID})
+	if err != nil {
+		return fmt.Errorf("coinpayments: error looking up ipn transaction - %v", err)
+	}
+
+	var discrepancies []string
+
+	if status := PaymentStatus(ipnStatus); statusClass(status) != statusClass(info.Status) {
+		discrepancies = append(discrepancies, fmt.Sprintf("ipn status %d is %v but api status %d is %v", status, statusClass(status), info.Status, statusClass(info.Status)))
+	}
+
+	if !coin.Equal(info.Coin) {
+		discrepancies = append(discrepancies, fmt.Sprintf("ipn coin %q does not match api coin %q", coin, info.Coin))
+	}
+
+	if !ipnReceived.Equal(info.Receivedf) {
+		discrepancies = append(discrepancies, fmt.Sprintf("ipn received_amount %v does not match api receivedf %v", ipnReceived, info.Receivedf))
+	}
+
+	if len(discrepancies) > 0 {
+		return &ReconciliationError{TransactionID: txnID, Discrepancies: discrepancies}
+	}
+	return nil
+}
+
+//statusClass returns whether a status is failed, pending or complete
+func statusClass(status PaymentStatus) string {
+	switch {
+	case status.IsFailed():
+		return "failed"
+	case status.IsComplete():
+		return "complete"
+	}
+	return "pending"
+}
+
+//received returns the coin a payment IPN was paid in and the amount received
+func (i *IPN) received() (Currency, Amount) {
+	switch i.IPNType {
+	case "simple":
+		return i.simpleButtonFields.Currency2, i.simpleButtonFields.ReceivedAmount
+	case "button":
+		return i.advancedButtonFields.Currency2, i.advancedButtonFields.ReceivedAmount
+	case "cart":
+		return i.shoppingCartButtonFields.Currency2, i.shoppingCartButtonFields.ReceivedAmount
+	case "donation":
+		return i.donationButtonFields.Currency2, i.donationButtonFields.ReceivedAmount
+	case "api":
+		return i.apiGeneratedTransactionFields.Currency2, i.apiGeneratedTransactionFields.ReceivedAmount
+	}
+	return "", Amount{}
+}
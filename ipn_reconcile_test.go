@@ -0,0 +1,188 @@
+package coinpayments
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+//txInfoTransport answers every api call with the configured get_tx_info result or error
+type txInfoTransport struct {
+	mu     sync.Mutex
+	result string
+	err    string
+	txids  []string
+}
+
+func (tr *txInfoTransport) set(result, err string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.result, tr.err, tr.txids = result, err, nil
+}
+
+func (tr *txInfoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.txids = append(tr.txids, values.Get("txid"))
+	body := `{"error":"ok","result":` + tr.result + `}`
+	if tr.err != "" {
+		body = `{"error":"` + tr.err + `"}`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func newTxInfoClient() (*Client, *txInfoTransport) {
+	transport := &txInfoTransport{}
+	return NewClient("public", "private", WithHTTPClient(&http.Client{Transport: transport})), transport
+}
+
+func TestReconcileIPN(t *testing.T) {
+	client, transport := newTxInfoClient()
+
+	tests := []struct {
+		name          string
+		builder       *IPNBuilder
+		info          string
+		discrepancies []string
+	}{
+		{
+			name:    "matching",
+			builder: NewIPNBuilder("api"),
+			info:    `{"status":100,"coin":"btc","receivedf":"0.00100000"}`,
+		},
+		{
+			name:    "both pending with different statuses",
+			builder: NewIPNBuilder("button").Set("status", "0"),
+			info:    `{"status":1,"coin":"BTC","receivedf":"0.00100000"}`,
+		},
+		{
+			name:          "pending ipn for a complete transaction",
+			builder:       NewIPNBuilder("button").Set("status", "0"),
+			info:          `{"status":100,"coin":"BTC","receivedf":"0.00100000"}`,
+			discrepancies: []string{"ipn status 0 is pending but api status 100 is complete"},
+		},
+		{
+			name:          "failed ipn for a complete transaction",
+			builder:       NewIPNBuilder("api").Set("status", "-1"),
+			info:          `{"status":100,"coin":"BTC","receivedf":"0.00100000"}`,
+			discrepancies: []string{"ipn status -1 is failed but api status 100 is complete"},
+		},
+		{
+			name:          "complete ipn for a pending transaction",
+			builder:       NewIPNBuilder("api"),
+			info:          `{"status":1,"coin":"BTC","receivedf":"0.00100000"}`,
+			discrepancies: []string{"ipn status 100 is complete but api status 1 is pending"},
+		},
+		{
+			name:          "ipn reports less than the api",
+			builder:       NewIPNBuilder("api").Set("received_amount", "0.0004"),
+			info:          `{"status":100,"coin":"BTC","receivedf":"0.00100000"}`,
+			discrepancies: []string{"ipn received_amount 0.0004 does not match api receivedf 0.001"},
+		},
+		{
+			name:    "every discrepancy",
+			builder: NewIPNBuilder("simple"),
+			info:    `{"status":0,"coin":"LTC","receivedf":"0.0005"}`,
+			discrepancies: []string{
+				"ipn status 100 is complete but api status 0 is pending",
+				`ipn coin "BTC" does not match api coin "LTC"`,
+				"ipn received_amount 0.001 does not match api receivedf 0.0005",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport.set(test.info, "")
+
+			err := client.ReconcileIPN(testIPN(t, test.builder.Values()))
+			if len(test.discrepancies) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			reconciliation, ok := err.(*ReconciliationError)
+			if !ok {
+				t.Fatalf("got error %v, want *ReconciliationError", err)
+			}
+			if got, want := strings.Join(reconciliation.Discrepancies, "; "), strings.Join(test.discrepancies, "; "); got != want {
+				t.Errorf("Discrepancies = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestReconcileIPNLooksUpTransaction(t *testing.T) {
+	client, transport := newTxInfoClient()
+
+	transport.set(`{"status":100,"coin":"BTC","receivedf":"0.001"}`, "")
+	if err := client.ReconcileIPN(testIPN(t, NewIPNBuilder("api").Set("txn_id", "CPTX1").Values())); err != nil {
+		t.Fatal(err)
+	}
+	if len(transport.txids) != 1 || transport.txids[0] != "CPTX1" {
+		t.Errorf("looked up %q, want [CPTX1]", transport.txids)
+	}
+
+	transport.set(`{}`, "")
+	for _, ipnType := range []string{"deposit", "withdrawal"} {
+		if err := client.ReconcileIPN(testIPN(t, NewIPNBuilder(ipnType).Values())); err != nil {
+			t.Errorf("%v: unexpected error %v", ipnType, err)
+		}
+	}
+	if len(transport.txids) != 0 {
+		t.Error("deposit and withdrawal ipns should not be looked up")
+	}
+
+	transport.set("", "Invalid transaction ID")
+	if err := client.ReconcileIPN(testIPN(t, NewIPNBuilder("api").Values())); err == nil {
+		t.Error("expected the lookup error")
+	}
+}
+
+func TestIPNHandlerReconcile(t *testing.T) {
+	client, transport := newTxInfoClient()
+
+	handler := NewIPNHandler(client, testIPNSecret)
+	handler.Reconcile(nil)
+	calls := 0
+	handler.OnAPI(func(ipn *ApiIPN) error {
+		calls++
+		return nil
+	})
+
+	transport.set(`{"status":0,"coin":"BTC","receivedf":"0"}`, "")
+	if w := serveIPN(handler, newBuiltIPNRequest(t, NewIPNBuilder("api"))); w.Code != http.StatusInternalServerError || calls != 0 {
+		t.Errorf("unconfirmed ipn: got %v with %v calls, want 500 and no calls", w.Code, calls)
+	}
+
+	transport.set(`{"status":100,"coin":"BTC","receivedf":"0.001"}`, "")
+	if w := serveIPN(handler, newBuiltIPNRequest(t, NewIPNBuilder("api"))); w.Code != http.StatusOK || calls != 1 {
+		t.Errorf("confirmed ipn: got %v with %v calls, want 200 and one call", w.Code, calls)
+	}
+
+	handler.Reconcile(func(ipn *IPN) bool { return false })
+	transport.set(`{"status":0,"coin":"BTC","receivedf":"0"}`, "")
+	if w := serveIPN(handler, newBuiltIPNRequest(t, NewIPNBuilder("api"))); w.Code != http.StatusOK || len(transport.txids) != 0 {
+		t.Errorf("filtered ipn: got %v with %v lookups, want 200 and no lookups", w.Code, len(transport.txids))
+	}
+}
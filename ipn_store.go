@@ -0,0 +1,280 @@
+package coinpayments
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//IPNStore records the keys of IPNs that have already been processed
+type IPNStore interface {
+	//Add records the key, returning false if it was already present
+	Add(key string) (bool, error)
+	//Remove forgets the key so that it can be added again
+	Remove(key string) error
+}
+
+//MemoryIPNStore is an in-memory IPNStore that forgets keys after a ttl
+type MemoryIPNStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	keys      map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+//NewMemoryIPNStore returns a new MemoryIPNStore that keeps keys for ttl. A ttl of 0 keeps keys forever
+func NewMemoryIPNStore(ttl time.Duration) *MemoryIPNStore {
+	return &MemoryIPNStore{
+		ttl:  ttl,
+		keys: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+//Add records the key, returning false if it was already present and has not expired
+func (s *MemoryIPNStore) Add(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if added, ok := s.keys[key]; ok && !s.expired(added, now) {
+		return false, nil
+	}
+	s.keys[key] = now
+	return true, nil
+}
+
+//Remove forgets the key
+func (s *MemoryIPNStore) Remove(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, key)
+	return nil
+}
+
+func (s *MemoryIPNStore) expired(added, now time.Time) bool {
+	return s.ttl > 0 && now.Sub(added) >= s.ttl
+}
+
+func (s *MemoryIPNStore) sweep(now time.Time) {
+	if s.ttl <= 0 || now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+	for key, added := range s.keys {
+		if s.expired(added, now) {
+			delete(s.keys, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+//ipnStoreCompactLines is how many lines of a FileIPNStore may hold removed or expired keys before it is compacted
+const ipnStoreCompactLines = 1024
+
+//FileIPNStore is an IPNStore that persists keys to an append-only file, forgetting them after a ttl. The file is
+//compacted when it is opened and whenever the lines for removed or expired keys pass a threshold
+type FileIPNStore struct {
+	mem          *MemoryIPNStore
+	mu           sync.Mutex
+	path         string
+	file         *os.File
+	lines        int
+	compactAfter int
+}
+
+//NewFileIPNStore opens or creates the store at path, keeping keys for ttl. A ttl of 0 keeps keys forever
+func NewFileIPNStore(path string, ttl time.Duration) (*FileIPNStore, error) {
+	mem := NewMemoryIPNStore(ttl)
+	if err := loadIPNStoreFile(path, mem); err != nil {
+		return nil, err
+	}
+
+	store := &FileIPNStore{
+		mem:          mem,
+		path:         path,
+		compactAfter: ipnStoreCompactLines,
+	}
+	if err := store.compact(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+//Add records the key, returning false if it was already present and has not expired
+func (s *FileIPNStore) Add(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.compactIfStale(); err != nil {
+		return false, err
+	}
+
+	added, err := s.mem.Add(key)
+	if err != nil || !added {
+		return added, err
+	}
+
+	if err := s.append(fmt.Sprintf("A %d %s\n", s.mem.now().Unix(), url.QueryEscape(key))); err != nil {
+		_ = s.mem.Remove(key)
+		return false, err
+	}
+	return true, nil
+}
+
+//Remove forgets the key
+func (s *FileIPNStore) Remove(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.compactIfStale(); err != nil {
+		return err
+	}
+
+	if err := s.append(fmt.Sprintf("R %s\n", url.QueryEscape(key))); err != nil {
+		return err
+	}
+	return s.mem.Remove(key)
+}
+
+//Close closes the underlying file
+func (s *FileIPNStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+func (s *FileIPNStore) append(line string) error {
+	if _, err := s.file.WriteString(line); err != nil {
+		return fmt.Errorf("coinpayments: error writing ipn store - %v", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("coinpayments: error syncing ipn store - %v", err)
+	}
+	s.lines++
+	return nil
+}
+
+//compactIfStale compacts the file once the lines it holds for removed or expired keys pass compactAfter
+func (s *FileIPNStore) compactIfStale() error {
+	s.mem.mu.Lock()
+	s.mem.sweep(s.mem.now())
+	live := len(s.mem.keys)
+	s.mem.mu.Unlock()
+
+	if s.lines-live < s.compactAfter {
+		return nil
+	}
+	return s.compact()
+}
+
+//compact rewrites the file down to the unexpired keys held in memory and reopens it for appending
+func (s *FileIPNStore) compact() error {
+	s.mem.mu.Lock()
+	lines, err := writeIPNStoreFile(s.path, s.mem)
+	s.mem.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("coinpayments: error opening ipn store - %v", err)
+	}
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file = file
+	s.lines = lines
+	return nil
+}
+
+func loadIPNStoreFile(path string, mem *MemoryIPNStore) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("coinpayments: error opening ipn store - %v", err)
+	}
+	defer file.Close()
+
+	now := mem.now()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 3 && fields[0] == "A":
+			unix, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("coinpayments: malformed ipn store entry - %v", err)
+			}
+			key, err := url.QueryUnescape(fields[2])
+			if err != nil {
+				return fmt.Errorf("coinpayments: malformed ipn store entry - %v", err)
+			}
+			added := time.Unix(unix, 0)
+			if !mem.expired(added, now) {
+				mem.keys[key] = added
+			}
+		case len(fields) == 2 && fields[0] == "R":
+			key, err := url.QueryUnescape(fields[1])
+			if err != nil {
+				return fmt.Errorf("coinpayments: malformed ipn store entry - %v", err)
+			}
+			delete(mem.keys, key)
+		case len(fields) == 0:
+		default:
+			return fmt.Errorf("coinpayments: malformed ipn store entry %q", scanner.Text())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("coinpayments: error reading ipn store - %v", err)
+	}
+	return nil
+}
+
+//writeIPNStoreFile compacts the store file down to the unexpired keys held in memory, returning how many it wrote
+func writeIPNStoreFile(path string, mem *MemoryIPNStore) (int, error) {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("coinpayments: error writing ipn store - %v", err)
+	}
+
+	now := mem.now()
+	lines := 0
+	w := bufio.NewWriter(file)
+	for key, added := range mem.keys {
+		if mem.expired(added, now) {
+			continue
+		}
+		fmt.Fprintf(w, "A %d %s\n", added.Unix(), url.QueryEscape(key))
+		lines++
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return 0, fmt.Errorf("coinpayments: error writing ipn store - %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return 0, fmt.Errorf("coinpayments: error syncing ipn store - %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("coinpayments: error writing ipn store - %v", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return 0, fmt.Errorf("coinpayments: error replacing ipn store - %v", err)
+	}
+	return lines, nil
+}
@@ -0,0 +1,213 @@
+package coinpayments
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func assertAdd(t *testing.T, store IPNStore, key string, want bool) {
+	t.Helper()
+
+	added, err := store.Add(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != want {
+		t.Errorf("Add(%q) = %v, want %v", key, added, want)
+	}
+}
+
+func TestMemoryIPNStore(t *testing.T) {
+	clock := &testClock{now: time.Unix(1600000000, 0)}
+	store := NewMemoryIPNStore(time.Hour)
+	store.now = clock.Now
+
+	assertAdd(t, store, "a", true)
+	assertAdd(t, store, "a", false)
+	assertAdd(t, store, "b", true)
+
+	if err := store.Remove("a"); err != nil {
+		t.Fatal(err)
+	}
+	assertAdd(t, store, "a", true)
+
+	clock.Advance(59 * time.Minute)
+	assertAdd(t, store, "b", false)
+
+	clock.Advance(time.Minute)
+	assertAdd(t, store, "b", true)
+
+	clock.Advance(2 * time.Hour)
+	assertAdd(t, store, "c", true)
+	if _, ok := store.keys["a"]; ok {
+		t.Error("expired key a was not swept")
+	}
+}
+
+func TestMemoryIPNStoreWithoutTTL(t *testing.T) {
+	clock := &testClock{now: time.Unix(1600000000, 0)}
+	store := NewMemoryIPNStore(0)
+	store.now = clock.Now
+
+	assertAdd(t, store, "a", true)
+	clock.Advance(24 * 365 * time.Hour)
+	assertAdd(t, store, "a", false)
+}
+
+func testStorePath(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "ipnstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "ipns.log")
+}
+
+func TestFileIPNStorePersists(t *testing.T) {
+	path := testStorePath(t)
+
+	store, err := NewFileIPNStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertAdd(t, store, "ipn:1", true)
+	assertAdd(t, store, "txn:deposit:CP 1:100", true)
+	assertAdd(t, store, "ipn:2", true)
+	if err := store.Remove("ipn:2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = NewFileIPNStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	assertAdd(t, store, "ipn:1", false)
+	assertAdd(t, store, "txn:deposit:CP 1:100", false)
+	assertAdd(t, store, "ipn:2", true)
+}
+
+func TestFileIPNStoreCompacts(t *testing.T) {
+	path := testStorePath(t)
+	old := time.Now().Add(-2 * time.Hour).Unix()
+	recent := time.Now().Add(-time.Minute).Unix()
+
+	log := strings.Join([]string{
+		"A " + strconv.FormatInt(old, 10) + " expired",
+		"A " + strconv.FormatInt(recent, 10) + " kept",
+		"A " + strconv.FormatInt(recent, 10) + " removed",
+		"R removed",
+		"",
+	}, "\n")
+	if err := ioutil.WriteFile(path, []byte(log), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewFileIPNStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "A " + strconv.FormatInt(recent, 10) + " kept\n"; string(data) != want {
+		t.Errorf("compacted store = %q, want %q", data, want)
+	}
+
+	assertAdd(t, store, "kept", false)
+	assertAdd(t, store, "expired", true)
+	assertAdd(t, store, "removed", true)
+}
+
+func TestFileIPNStoreCompactsStaleLines(t *testing.T) {
+	path := testStorePath(t)
+	clock := &testClock{now: time.Now()}
+
+	store, err := NewFileIPNStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	store.mem.now = clock.Now
+	store.compactAfter = 4
+
+	countLines := func() int {
+		t.Helper()
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Count(string(data), "\n")
+	}
+
+	assertAdd(t, store, "kept", true)
+	for i := 0; i < 10; i++ {
+		key := "removed:" + strconv.Itoa(i)
+		assertAdd(t, store, key, true)
+		if err := store.Remove(key); err != nil {
+			t.Fatal(err)
+		}
+		if lines := countLines(); lines > 1+store.compactAfter+1 {
+			t.Fatalf("store has %v lines after %v removals, want it compacted", lines, i+1)
+		}
+	}
+
+	clock.Advance(2 * time.Hour)
+	for i := 0; i < 5; i++ {
+		assertAdd(t, store, "new:"+strconv.Itoa(i), true)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), " kept") {
+		t.Errorf("expired key was kept after compaction: %q", data)
+	}
+
+	reopened, err := NewFileIPNStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	reopened.mem.now = clock.Now
+	assertAdd(t, reopened, "new:4", false)
+	assertAdd(t, reopened, "removed:9", true)
+}
+
+func TestFileIPNStoreMalformed(t *testing.T) {
+	path := testStorePath(t)
+	if err := ioutil.WriteFile(path, []byte("X what\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewFileIPNStore(path, 0); err == nil {
+		t.Error("expected an error opening a malformed store")
+	}
+}
@@ -0,0 +1,61 @@
+package coinpayments
+
+import (
+	"net/url"
+	"strconv"
+)
+
+var knownIPNVersions = []string{"1.0"}
+
+var requiredIPNFields = map[string][]string{
+	"api":        {"txn_id", "status", "amount1", "amount2", "currency1", "currency2"},
+	"deposit":    {"txn_id", "status", "address", "amount", "currency"},
+	"withdrawal": {"id", "status", "address", "amount", "currency"},
+	"simple":     {"txn_id", "status", "amount1", "amount2", "currency1", "currency2"},
+	"button":     {"txn_id", "status", "amount1", "amount2", "currency1", "currency2"},
+	"cart":       {"txn_id", "status", "amount1", "amount2", "currency1", "currency2"},
+	"donation":   {"txn_id", "status", "amount1", "amount2", "currency1", "currency2"},
+}
+
+//numericIPNFields are the numeric fields kept as strings on an IPN. Amounts and statuses are checked as they are parsed
+var numericIPNFields = []string{"received_confirms", "confirms"}
+
+//WithStrict is an option that rejects IPNs of unknown types or versions, IPNs missing the fields required for their
+//type and IPNs with amounts or statuses that cannot be parsed. Every problem found is reported together in a
+//ValidationError. Without it such amounts and statuses are read as zero
+func WithStrict() IPNOption {
+	return func(config *ipnConfig) {
+		config.strict = true
+	}
+}
+
+func validateIPNValues(v *validator, values url.Values) {
+	for _, field := range []string{"ipn_version", "ipn_type", "ipn_mode", "ipn_id", "merchant"} {
+		if values.Get(field) == "" {
+			v.add(field, "is required")
+		}
+	}
+
+	if version := values.Get("ipn_version"); version != "" && !containsString(knownIPNVersions, version) {
+		v.add("ipn_version", "unknown version %q", version)
+	}
+
+	ipnType := values.Get("ipn_type")
+	required, ok := requiredIPNFields[ipnType]
+	if ipnType != "" && !ok {
+		v.add("ipn_type", "unknown type %q", ipnType)
+	}
+	for _, field := range required {
+		if values.Get(field) == "" {
+			v.add(field, "is required for ipn type %q", ipnType)
+		}
+	}
+
+	for _, field := range numericIPNFields {
+		if value := values.Get(field); value != "" {
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				v.add(field, "%q is not a number", value)
+			}
+		}
+	}
+}
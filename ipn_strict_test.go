@@ -0,0 +1,58 @@
+package coinpayments
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseIPNStrict(t *testing.T) {
+	client := NewClient("public", "private")
+
+	tests := []struct {
+		name    string
+		builder *IPNBuilder
+		fields  []string
+	}{
+		{name: "valid", builder: NewIPNBuilder("api")},
+		{name: "missing header fields", builder: NewIPNBuilder("deposit").Del("ipn_id").Del("merchant"), fields: []string{"ipn_id", "merchant"}},
+		{name: "unknown version", builder: NewIPNBuilder("deposit").Set("ipn_version", "9.9"), fields: []string{"ipn_version"}},
+		{name: "unknown type", builder: NewIPNBuilder("deposit").Set("ipn_type", "refund"), fields: []string{"ipn_type"}},
+		{name: "missing type fields", builder: NewIPNBuilder("withdrawal").Del("address").Del("currency"), fields: []string{"address", "currency"}},
+		{name: "non-numeric confirms", builder: NewIPNBuilder("deposit").Set("confirms", "three"), fields: []string{"confirms"}},
+		{
+			name:    "every problem together",
+			builder: NewIPNBuilder("api").Del("txn_id").Set("amount1", "ten").Set("received_confirms", "x"),
+			fields:  []string{"amount1", "received_confirms", "txn_id"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := client.ParseIPN(newBuiltIPNRequest(t, test.builder), testIPNSecret, WithStrict())
+			if len(test.fields) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			validation, ok := err.(*ValidationError)
+			if !ok {
+				t.Fatalf("got error %v, want *ValidationError", err)
+			}
+			var fields []string
+			for _, e := range validation.Errors {
+				fields = append(fields, e.Field)
+			}
+			sort.Strings(fields)
+			if strings.Join(fields, ",") != strings.Join(test.fields, ",") {
+				t.Errorf("fields = %v, want %v (%v)", fields, test.fields, err)
+			}
+
+			if _, err := client.ParseIPN(newBuiltIPNRequest(t, test.builder), testIPNSecret); err != nil {
+				t.Errorf("non-strict parsing rejected the ipn: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package coinpayments
+
+import (
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseIPNBodySize(t *testing.T) {
+	client := NewClient("public", "private")
+	body := NewIPNBuilder("deposit").Encode()
+	size := int64(len(body))
+
+	tests := []struct {
+		name      string
+		limit     int64
+		tooBig    bool
+		wantLimit int64
+	}{
+		{name: "exact limit", limit: size},
+		{name: "one byte under", limit: size - 1, tooBig: true, wantLimit: size - 1},
+		{name: "zero uses the default", limit: 0},
+		{name: "negative uses the default", limit: -1},
+		{name: "max int64 is lowered to the maximum", limit: math.MaxInt64},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/ipn", strings.NewReader(body))
+			_, err := client.ParseIPN(r, "", WithoutVerification(), WithMaxBodySize(test.limit))
+
+			if !test.tooBig {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			tooLarge, ok := err.(*IPNTooLargeError)
+			if !ok {
+				t.Fatalf("got error %v, want *IPNTooLargeError", err)
+			}
+			if tooLarge.Limit != test.wantLimit {
+				t.Errorf("Limit = %v, want %v", tooLarge.Limit, test.wantLimit)
+			}
+		})
+	}
+}
+
+func TestWithMaxBodySizeBounds(t *testing.T) {
+	tests := []struct {
+		limit int64
+		want  int64
+	}{
+		{limit: 0, want: defaultMaxIPNBodySize},
+		{limit: 512, want: 512},
+		{limit: maxIPNBodySize, want: maxIPNBodySize},
+		{limit: maxIPNBodySize + 1, want: maxIPNBodySize},
+		{limit: math.MaxInt64, want: maxIPNBodySize},
+	}
+
+	for _, test := range tests {
+		if got := newIPNConfig([]IPNOption{WithMaxBodySize(test.limit)}).maxBodySize; got != test.want {
+			t.Errorf("WithMaxBodySize(%v) = %v, want %v", test.limit, got, test.want)
+		}
+	}
+}
+
+func TestParseIPNDefaultBodySize(t *testing.T) {
+	client := NewClient("public", "private")
+	body := "ipn_type=deposit&pad=" + strings.Repeat("x", defaultMaxIPNBodySize)
+
+	r := httptest.NewRequest(http.MethodPost, "/ipn", strings.NewReader(body))
+	if _, err := client.ParseIPN(r, "", WithoutVerification()); err == nil {
+		t.Fatal("expected a body over 1MiB to be rejected")
+	} else if _, ok := err.(*IPNTooLargeError); !ok {
+		t.Fatalf("got error %v, want *IPNTooLargeError", err)
+	}
+}
+
+func TestParseIPNRestoresBody(t *testing.T) {
+	client := NewClient("public", "private")
+	builder := NewIPNBuilder("deposit")
+	r := newBuiltIPNRequest(t, builder)
+
+	if _, err := client.ParseIPN(r, testIPNSecret); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != builder.Encode() {
+		t.Errorf("restored body = %q, want %q", data, builder.Encode())
+	}
+}
+
+func TestParseIPNWithoutBody(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/ipn", nil)
+	r.Body = nil
+	if _, err := NewClient("public", "private").ParseIPN(r, testIPNSecret); err == nil {
+		t.Error("expected an error for a request without a body")
+	}
+}
+
+func TestParseIPNBytes(t *testing.T) {
+	client := NewClient("public", "private")
+	body, hmac := NewIPNBuilder("withdrawal").Sign(testIPNSecret)
+
+	ipn, err := client.ParseIPNBytes([]byte(body), hmac, testIPNSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ipn.IPNType != "withdrawal" {
+		t.Errorf("IPNType = %q, want withdrawal", ipn.IPNType)
+	}
+
+	if _, err := client.ParseIPNBytes([]byte(body), "bad", testIPNSecret); err == nil {
+		t.Error("expected a bad hmac to be rejected")
+	}
+}
+
+func TestParseIPNBytesHTTPAuth(t *testing.T) {
+	client := NewClient("public", "private")
+	body := NewIPNBuilder("deposit").Set("ipn_mode", ipnModeHTTPAuth).Encode()
+
+	if _, err := client.ParseIPNBytes([]byte(body), "", testIPNSecret, WithHTTPAuth("user", "pass")); err == nil {
+		t.Error("expected an httpauth ipn to be rejected without a request")
+	}
+}
+
+func TestParseIPNMalformedAmounts(t *testing.T) {
+	client := NewClient("public", "private")
+	builder := NewIPNBuilder("deposit").Set("amount", "1,5").Set("amounti", "lots").Set("status", "done")
+
+	r := newBuiltIPNRequest(t, builder)
+	ipn, err := client.ParseIPN(r, testIPNSecret)
+	if err != nil {
+		t.Fatalf("non-strict parsing should tolerate malformed amounts: %v", err)
+	}
+	deposit, err := ipn.ToDepositIPN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !deposit.Amount.IsZero() || !deposit.Amounti.IsZero() || deposit.Status != 0 {
+		t.Errorf("malformed fields read as %v, %v and %v, want zero values", deposit.Amount, deposit.Amounti, deposit.Status)
+	}
+	if got := deposit.Fee.String(); got != "0.000005" {
+		t.Errorf("Fee = %v, want 0.000005", got)
+	}
+
+	r = newBuiltIPNRequest(t, builder)
+	_, err = client.ParseIPN(r, testIPNSecret, WithStrict())
+	validation, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("got error %v, want *ValidationError", err)
+	}
+	fields := map[string]bool{}
+	for _, e := range validation.Errors {
+		fields[e.Field] = true
+	}
+	for _, field := range []string{"amount", "amounti", "status"} {
+		if !fields[field] {
+			t.Errorf("strict parsing did not report %v in %v", field, err)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package coinpayments
+
+import (
+	"crypto/hmac"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+)
+
+const (
+	ipnModeHMAC     = "hmac"
+	ipnModeHTTPAuth = "httpauth"
+
+	defaultMaxIPNBodySize = 1 << 20
+	maxIPNBodySize        = 64 << 20
+)
+
+//IPNOption is an option used to modify how an IPN is verified
+type IPNOption func(config *ipnConfig)
+
+type ipnConfig struct {
+	skipVerification bool
+	maxBodySize      int64
+	strict           bool
+	authUsername     string
+	authPassword     string
+	merchantID       string
+	modes            []string
+	types            []string
+	version          string
+}
+
+func newIPNConfig(options []IPNOption) *ipnConfig {
+	config := &ipnConfig{
+		maxBodySize: defaultMaxIPNBodySize,
+	}
+	for _, o := range options {
+		o(config)
+	}
+	return config
+}
+
+//WithHTTPAuth is an option that accepts IPNs sent with ipn_mode 'httpauth' using the provided basic auth credentials,
+//as well as IPNs sent in 'hmac' mode. Without it or WithIPNModes only 'hmac' IPNs are accepted
+func WithHTTPAuth(username, password string) IPNOption {
+	return func(config *ipnConfig) {
+		config.authUsername = username
+		config.authPassword = password
+	}
+}
+
+//WithoutVerification is an option that accepts IPNs without verifying their HMAC or credentials. It should only be used in testing
+func WithoutVerification() IPNOption {
+	return func(config *ipnConfig) {
+		config.skipVerification = true
+	}
+}
+
+//WithMaxBodySize is an option that rejects IPN bodies larger than the provided number of bytes. The default is 1MiB,
+//which is also used for values below 1, and values above 64MiB are lowered to 64MiB
+func WithMaxBodySize(bytes int64) IPNOption {
+	return func(config *ipnConfig) {
+		if bytes < 1 {
+			bytes = defaultMaxIPNBodySize
+		}
+		if bytes > maxIPNBodySize {
+			bytes = maxIPNBodySize
+		}
+		config.maxBodySize = bytes
+	}
+}
+
+//WithMerchantID is an option that rejects IPNs not addressed to the provided merchant id
+func WithMerchantID(merchantID string) IPNOption {
+	return func(config *ipnConfig) {
+		config.merchantID = merchantID
+	}
+}
+
+//WithIPNModes is an option that rejects IPNs whose ipn_mode is not one of the provided modes. It replaces the
+//accepted modes otherwise decided by WithHTTPAuth
+func WithIPNModes(modes ...string) IPNOption {
+	return func(config *ipnConfig) {
+		config.modes = modes
+	}
+}
+
+//WithIPNTypes is an option that rejects IPNs whose ipn_type is not one of the provided types
+func WithIPNTypes(types ...string) IPNOption {
+	return func(config *ipnConfig) {
+		config.types = types
+	}
+}
+
+//WithIPNVersion is an option that rejects IPNs whose ipn_version does not match the provided version
+func WithIPNVersion(version string) IPNOption {
+	return func(config *ipnConfig) {
+		config.version = version
+	}
+}
+
+//IPNTooLargeError is returned when an IPN body is larger than the maximum body size
+type IPNTooLargeError struct {
+	Limit int64
+}
+
+func (e *IPNTooLargeError) Error() string {
+	return fmt.Sprintf("coinpayments: ipn body larger than %v bytes", e.Limit)
+}
+
+//IPNVerificationError is returned when an IPN fails one or more of the configured checks
+type IPNVerificationError struct {
+	Failures []string
+}
+
+func (e *IPNVerificationError) Error() string {
+	return fmt.Sprintf("coinpayments: ipn failed verification - %v", strings.Join(e.Failures, "; "))
+}
+
+func (c *Client) authenticateIPN(data []byte, mode, hmacHeader string, basicAuth func() (string, string, bool), ipnSecret string, config *ipnConfig) error {
+	if config.skipVerification {
+		return nil
+	}
+
+	//the ipn_mode in the body is not authenticated yet, so it may only pick between modes the receiver accepts
+	if modes := config.acceptedModes(); !containsString(modes, mode) {
+		return &IPNVerificationError{Failures: []string{fmt.Sprintf("ipn_mode %q not in %v", mode, modes)}}
+	}
+
+	switch mode {
+	case ipnModeHMAC:
+		if ipnSecret == "" {
+			return fmt.Errorf("coinpayments: ipn sent in 'hmac' mode but no ipn secret is configured")
+		}
+
+		genHMAC, err := c.makeIPNHMAC(string(data), ipnSecret)
+		if err != nil {
+			return fmt.Errorf("coinpayments: error generating ipn HMAC - %v", err)
+		}
+
+		if !hmac.Equal([]byte(hmacHeader), []byte(genHMAC)) {
+			return fmt.Errorf("coinpayments: could not validate server HMAC")
+		}
+	case ipnModeHTTPAuth:
+		if config.authUsername == "" && config.authPassword == "" {
+			return fmt.Errorf("coinpayments: ipn sent in 'httpauth' mode but no credentials are configured")
+		}
+
+		if basicAuth == nil {
+			return fmt.Errorf("coinpayments: ipn sent in 'httpauth' mode without basic auth credentials")
+		}
+
+		username, password, ok := basicAuth()
+		if !ok {
+			return fmt.Errorf("coinpayments: ipn sent in 'httpauth' mode without basic auth credentials")
+		}
+
+		userMatch := subtle.ConstantTimeCompare([]byte(username), []byte(config.authUsername))
+		passMatch := subtle.ConstantTimeCompare([]byte(password), []byte(config.authPassword))
+		if userMatch&passMatch != 1 {
+			return fmt.Errorf("coinpayments: could not validate ipn basic auth credentials")
+		}
+	default:
+		return fmt.Errorf("coinpayments: unsupported ipn mode %q", mode)
+	}
+
+	return nil
+}
+
+//acceptedModes returns the modes set with WithIPNModes, or 'hmac' and, once WithHTTPAuth has been provided,
+//'httpauth'
+func (config *ipnConfig) acceptedModes() []string {
+	if len(config.modes) > 0 {
+		return config.modes
+	}
+	if config.authUsername != "" || config.authPassword != "" {
+		return []string{ipnModeHMAC, ipnModeHTTPAuth}
+	}
+	return []string{ipnModeHMAC}
+}
+
+func (config *ipnConfig) verify(info *ipnInformation) error {
+	var failures []string
+
+	if config.merchantID != "" && info.Merchant != config.merchantID {
+		failures = append(failures, fmt.Sprintf("merchant %q does not match %q", info.Merchant, config.merchantID))
+	}
+	if len(config.modes) > 0 && !containsString(config.modes, info.IPNMode) {
+		failures = append(failures, fmt.Sprintf("ipn_mode %q not in %v", info.IPNMode, config.modes))
+	}
+	if len(config.types) > 0 && !containsString(config.types, info.IPNType) {
+		failures = append(failures, fmt.Sprintf("ipn_type %q not in %v", info.IPNType, config.types))
+	}
+	if config.version != "" && info.IPNVersion != config.version {
+		failures = append(failures, fmt.Sprintf("ipn_version %q does not match %q", info.IPNVersion, config.version))
+	}
+
+	if len(failures) > 0 {
+		return &IPNVerificationError{Failures: failures}
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
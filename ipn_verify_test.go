@@ -0,0 +1,265 @@
+package coinpayments
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testIPNSecret = "test-ipn-secret"
+
+func testIPNValues(ipnType string) url.Values {
+	return url.Values{
+		"ipn_version": {"1.0"},
+		"ipn_type":    {ipnType},
+		"ipn_mode":    {"hmac"},
+		"ipn_id":      {"IPN1"},
+		"merchant":    {"TESTMERCHANT"},
+	}
+}
+
+func signTestIPN(body, secret string) string {
+	hash := hmac.New(sha512.New, []byte(secret))
+	hash.Write([]byte(body))
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+func newSignedIPNRequest(values url.Values) *http.Request {
+	body := values.Encode()
+	r := httptest.NewRequest(http.MethodPost, "/ipn", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("HMAC", signTestIPN(body, testIPNSecret))
+	return r
+}
+
+func TestParseIPNVerification(t *testing.T) {
+	client := NewClient("public", "private")
+
+	tests := []struct {
+		name     string
+		values   url.Values
+		options  []IPNOption
+		failures []string
+	}{
+		{
+			name:   "matching checks",
+			values: testIPNValues("deposit"),
+			options: []IPNOption{
+				WithMerchantID("TESTMERCHANT"),
+				WithIPNModes("hmac"),
+				WithIPNTypes("deposit", "withdrawal"),
+				WithIPNVersion("1.0"),
+			},
+		},
+		{
+			name:   "no checks",
+			values: url.Values{"ipn_type": {"deposit"}, "ipn_mode": {"hmac"}},
+		},
+		{
+			name:     "other merchant",
+			values:   url.Values{"ipn_type": {"deposit"}, "ipn_mode": {"hmac"}, "merchant": {"OTHER"}},
+			options:  []IPNOption{WithMerchantID("TESTMERCHANT")},
+			failures: []string{`merchant "OTHER" does not match "TESTMERCHANT"`},
+		},
+		{
+			name:     "mode not allowed",
+			values:   testIPNValues("deposit"),
+			options:  []IPNOption{WithIPNModes("httpauth")},
+			failures: []string{`ipn_mode "hmac" not in [httpauth]`},
+		},
+		{
+			name:   "every check failing",
+			values: url.Values{"ipn_type": {"api"}, "ipn_mode": {"hmac"}, "merchant": {"OTHER"}, "ipn_version": {"2.0"}},
+			options: []IPNOption{
+				WithMerchantID("TESTMERCHANT"),
+				WithIPNTypes("deposit"),
+				WithIPNVersion("1.0"),
+			},
+			failures: []string{
+				`merchant "OTHER" does not match "TESTMERCHANT"`,
+				`ipn_type "api" not in [deposit]`,
+				`ipn_version "2.0" does not match "1.0"`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := client.ParseIPN(newSignedIPNRequest(test.values), testIPNSecret, test.options...)
+
+			if len(test.failures) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			verification, ok := err.(*IPNVerificationError)
+			if !ok {
+				t.Fatalf("got error %v, want *IPNVerificationError", err)
+			}
+			if strings.Join(verification.Failures, "; ") != strings.Join(test.failures, "; ") {
+				t.Errorf("Failures = %q, want %q", verification.Failures, test.failures)
+			}
+		})
+	}
+}
+
+func TestParseIPNHMAC(t *testing.T) {
+	client := NewClient("public", "private")
+	values := testIPNValues("deposit")
+
+	ipn, err := client.ParseIPN(newSignedIPNRequest(values), testIPNSecret)
+	if err != nil {
+		t.Fatalf("valid hmac rejected: %v", err)
+	}
+	if ipn.IPNType != "deposit" || ipn.IPNId != "IPN1" || ipn.Merchant != "TESTMERCHANT" {
+		t.Errorf("unexpected ipn information %+v", ipn.ipnInformation)
+	}
+
+	r := newSignedIPNRequest(values)
+	r.Header.Set("HMAC", signTestIPN(values.Encode(), "wrong-secret"))
+	if _, err := client.ParseIPN(r, testIPNSecret); err == nil {
+		t.Error("expected an hmac signed with another secret to be rejected")
+	}
+
+	if _, err := client.ParseIPN(newSignedIPNRequest(values), ""); err == nil {
+		t.Error("expected an error verifying an hmac ipn without a secret")
+	}
+
+	values.Set("ipn_mode", "other")
+	if _, err := client.ParseIPN(newSignedIPNRequest(values), testIPNSecret, WithIPNModes("hmac", "other")); err == nil || !strings.Contains(err.Error(), "unsupported ipn mode") {
+		t.Errorf("got error %v, want an unsupported ipn mode error", err)
+	}
+}
+
+func TestParseIPNAcceptedModes(t *testing.T) {
+	client := NewClient("public", "private")
+
+	tests := []struct {
+		name    string
+		mode    string
+		options []IPNOption
+		want    string
+	}{
+		{name: "hmac by default", mode: "hmac"},
+		{name: "httpauth not configured", mode: "httpauth", want: `ipn_mode "httpauth" not in [hmac]`},
+		{name: "unknown mode", mode: "other", want: `ipn_mode "other" not in [hmac]`},
+		{name: "empty mode", mode: "", want: `ipn_mode "" not in [hmac]`},
+		{name: "hmac with httpauth configured", mode: "hmac", options: []IPNOption{WithHTTPAuth("user", "pass")}},
+		{name: "httpauth configured", mode: "httpauth", options: []IPNOption{WithHTTPAuth("user", "pass")}},
+		{
+			name:    "hmac excluded by modes",
+			mode:    "hmac",
+			options: []IPNOption{WithHTTPAuth("user", "pass"), WithIPNModes("httpauth")},
+			want:    `ipn_mode "hmac" not in [httpauth]`,
+		},
+		{
+			name:    "httpauth excluded by modes",
+			mode:    "httpauth",
+			options: []IPNOption{WithHTTPAuth("user", "pass"), WithIPNModes("hmac")},
+			want:    `ipn_mode "httpauth" not in [hmac]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values := testIPNValues("deposit")
+			values.Set("ipn_mode", test.mode)
+			r := newSignedIPNRequest(values)
+			r.SetBasicAuth("user", "pass")
+
+			_, err := client.ParseIPN(r, testIPNSecret, test.options...)
+			if test.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			verification, ok := err.(*IPNVerificationError)
+			if !ok {
+				t.Fatalf("got error %v, want *IPNVerificationError", err)
+			}
+			if got := strings.Join(verification.Failures, "; "); got != test.want {
+				t.Errorf("Failures = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateIPNRejectsModeBeforeCredentials(t *testing.T) {
+	client := NewClient("public", "private")
+	config := newIPNConfig([]IPNOption{WithIPNModes("hmac")})
+
+	checked := false
+	basicAuth := func() (string, string, bool) {
+		checked = true
+		return "user", "pass", true
+	}
+	if err := client.authenticateIPN([]byte("ipn_mode=httpauth"), "httpauth", "", basicAuth, testIPNSecret, config); err == nil {
+		t.Error("expected an unaccepted mode to be rejected")
+	}
+	if checked {
+		t.Error("credentials were checked for an ipn_mode that is not accepted")
+	}
+}
+
+func TestParseIPNHTTPAuth(t *testing.T) {
+	client := NewClient("public", "private")
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		noAuth   bool
+		options  []IPNOption
+		wantErr  bool
+	}{
+		{name: "matching credentials", username: "user", password: "pass", options: []IPNOption{WithHTTPAuth("user", "pass")}},
+		{name: "wrong password", username: "user", password: "nope", options: []IPNOption{WithHTTPAuth("user", "pass")}, wantErr: true},
+		{name: "wrong username", username: "admin", password: "pass", options: []IPNOption{WithHTTPAuth("user", "pass")}, wantErr: true},
+		{name: "no credentials sent", noAuth: true, options: []IPNOption{WithHTTPAuth("user", "pass")}, wantErr: true},
+		{name: "no credentials configured", username: "user", password: "pass", wantErr: true},
+		{name: "verification skipped", noAuth: true, options: []IPNOption{WithoutVerification()}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values := testIPNValues("deposit")
+			values.Set("ipn_mode", "httpauth")
+			r := newSignedIPNRequest(values)
+			r.Header.Del("HMAC")
+			if !test.noAuth {
+				r.SetBasicAuth(test.username, test.password)
+			}
+
+			_, err := client.ParseIPN(r, testIPNSecret, test.options...)
+			if test.wantErr && err == nil {
+				t.Error("expected an error")
+			}
+			if !test.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseIPNWithoutVerification(t *testing.T) {
+	client := NewClient("public", "private")
+
+	r := newSignedIPNRequest(testIPNValues("deposit"))
+	r.Header.Del("HMAC")
+	if _, err := client.ParseIPN(r, ""); err == nil {
+		t.Error("expected an unsigned ipn to be rejected without WithoutVerification")
+	}
+
+	r = newSignedIPNRequest(testIPNValues("deposit"))
+	r.Header.Del("HMAC")
+	if _, err := client.ParseIPN(r, "", WithoutVerification()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package coinpayments
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+//RatesCacheOption is an option used to modify a RatesCache
+type RatesCacheOption func(cache *RatesCache)
+
+const (
+	defaultRatesRefreshInterval = time.Minute
+	defaultRatesMaxStaleness    = 10 * time.Minute
+)
+
+//WithRefreshInterval is an option that sets how often the cache fetches new rates in the background. The default
+//is one minute, which is also used for values below 1
+func WithRefreshInterval(interval time.Duration) RatesCacheOption {
+	return func(cache *RatesCache) {
+		if interval <= 0 {
+			interval = defaultRatesRefreshInterval
+		}
+		cache.interval = interval
+	}
+}
+
+//WithMaxStaleness is an option that sets the oldest rates the cache will serve. The default is ten minutes, which
+//is also used for values below 1. It cannot be shorter than the refresh interval
+func WithMaxStaleness(maxStaleness time.Duration) RatesCacheOption {
+	return func(cache *RatesCache) {
+		if maxStaleness <= 0 {
+			maxStaleness = defaultRatesMaxStaleness
+		}
+		cache.maxStaleness = maxStaleness
+	}
+}
+
+//WithRatesRequest is an option that sets the request the cache fetches rates with. A nil request fetches rates
+//with the default RatesRequest
+func WithRatesRequest(request *RatesRequest) RatesCacheOption {
+	return func(cache *RatesCache) {
+		if request == nil {
+			request = &RatesRequest{}
+		}
+		cache.request = request
+	}
+}
+
+//RatesSnapshot is the rates returned by one successful rates call. The same snapshot is handed to every caller of
+//Get and every subscriber, so it must be treated as read-only
+type RatesSnapshot struct {
+	Rates     RatesResponse
+	FetchedAt time.Time
+}
+
+//Age returns how long ago the rates were fetched
+func (s *RatesSnapshot) Age() time.Duration {
+	return time.Since(s.FetchedAt)
+}
+
+//Catalog builds a Catalog from the rates
+func (s *RatesSnapshot) Catalog() *Catalog {
+	return NewCatalog(s.Rates)
+}
+
+//StaleRatesError is returned by a RatesCache that has no rates newer than its maximum staleness. FetchedAt is zero
+//if rates were never fetched, and LastError holds the error of the latest failed refresh
+type StaleRatesError struct {
+	FetchedAt    time.Time
+	MaxStaleness time.Duration
+	LastError    error
+}
+
+func (e *StaleRatesError) Error() string {
+	message := "coinpayments: no rates have been fetched"
+	if !e.FetchedAt.IsZero() {
+		message = fmt.Sprintf("coinpayments: rates fetched at %v are older than %v", e.FetchedAt.Format(time.RFC3339), e.MaxStaleness)
+	}
+	if e.LastError != nil {
+		message += fmt.Sprintf(" - %v", e.LastError)
+	}
+	return message
+}
+
+//RatesCache serves rates from memory, refreshing them in the background so that pages showing prices do not call
+//the api on every request. It keeps serving the last good rates through failed refreshes until they are older than
+//its maximum staleness
+type RatesCache struct {
+	api          API
+	request      *RatesRequest
+	interval     time.Duration
+	maxStaleness time.Duration
+
+	refreshMu sync.Mutex
+
+	mu          sync.RWMutex
+	snapshot    *RatesSnapshot
+	lastErr     error
+	subscribers map[int]func(snapshot *RatesSnapshot)
+	nextID      int
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+//NewRatesCache returns a new RatesCache fetching rates from api, which is usually a *Client. It holds no rates until
+//it is started or refreshed
+func NewRatesCache(api API, options ...RatesCacheOption) *RatesCache {
+	cache := &RatesCache{
+		api:          api,
+		request:      &RatesRequest{},
+		interval:     defaultRatesRefreshInterval,
+		maxStaleness: defaultRatesMaxStaleness,
+		subscribers:  make(map[int]func(snapshot *RatesSnapshot)),
+	}
+
+	for _, o := range options {
+		o(cache)
+	}
+	return cache
+}
+
+//Start fetches rates and, whether or not that succeeds, keeps refreshing them in the background every refresh
+//interval until Stop is called. The error of the first fetch is returned. Start fails without fetching if the
+//maximum staleness is shorter than the refresh interval, as the cache would serve no rates between refreshes
+func (c *RatesCache) Start() error {
+	if c.maxStaleness < c.interval {
+		return fmt.Errorf("coinpayments: rates cache max staleness %v is shorter than its refresh interval %v", c.maxStaleness, c.interval)
+	}
+
+	c.mu.Lock()
+	if c.stop != nil {
+		c.mu.Unlock()
+		return fmt.Errorf("coinpayments: rates cache already started")
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	c.stop, c.done = stop, done
+	c.mu.Unlock()
+
+	_, err := c.Refresh()
+	go c.run(stop, done)
+	return err
+}
+
+//Stop stops the background refresh, waiting for a refresh in progress to finish
+func (c *RatesCache) Stop() {
+	c.mu.Lock()
+	stop, done := c.stop, c.done
+	c.stop, c.done = nil, nil
+	c.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+func (c *RatesCache) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			_, _ = c.Refresh()
+		}
+	}
+}
+
+//Refresh fetches rates now. On success the new rates are stored and passed to every subscriber, on failure the
+//error is returned and the previous rates are kept
+func (c *RatesCache) Refresh() (*RatesSnapshot, error) {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	request := *c.request
+	rates, err := c.api.Rates(&request)
+	if err == nil && rates == nil {
+		err = fmt.Errorf("coinpayments: rates call returned no result")
+	}
+	if err != nil {
+		c.mu.Lock()
+		c.lastErr = err
+		c.mu.Unlock()
+		return nil, err
+	}
+
+	snapshot := &RatesSnapshot{Rates: *rates, FetchedAt: time.Now()}
+
+	c.mu.Lock()
+	c.snapshot = snapshot
+	c.lastErr = nil
+	subscribers := make([]func(snapshot *RatesSnapshot), 0, len(c.subscribers))
+	for _, fn := range c.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	c.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(snapshot)
+	}
+	return snapshot, nil
+}
+
+//Get returns the last good rates, or a StaleRatesError if there are none or they are older than the maximum
+//staleness. The snapshot is shared and must not be modified
+func (c *RatesCache) Get() (*RatesSnapshot, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.snapshot == nil || c.snapshot.Age() > c.maxStaleness {
+		err := &StaleRatesError{MaxStaleness: c.maxStaleness, LastError: c.lastErr}
+		if c.snapshot != nil {
+			err.FetchedAt = c.snapshot.FetchedAt
+		}
+		return nil, err
+	}
+	return c.snapshot, nil
+}
+
+//LastError returns the error of the latest refresh, or nil if it succeeded
+func (c *RatesCache) LastError() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.lastErr
+}
+
+//Subscribe calls fn with the new rates after every successful refresh, until the returned function is called. fn
+//runs on the refreshing goroutine, so it should not block, and shares the snapshot, so it must not modify it
+func (c *RatesCache) Subscribe(fn func(snapshot *RatesSnapshot)) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.nextID
+	c.nextID++
+	c.subscribers[id] = fn
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		delete(c.subscribers, id)
+	}
+}
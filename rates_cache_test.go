@@ -0,0 +1,211 @@
+package coinpayments_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aidenesco/coinpayments"
+	"github.com/aidenesco/coinpayments/coinpaymentstest"
+)
+
+//ratesFake answers rates calls with a BTC rate of the number of calls made, or with err once it is set
+type ratesFake struct {
+	coinpaymentstest.Fake
+
+	mu    sync.Mutex
+	count int
+	err   error
+}
+
+func newRatesFake() *ratesFake {
+	f := &ratesFake{}
+	f.RatesFunc = func(request *coinpayments.RatesRequest) (*coinpayments.RatesResponse, error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if f.err != nil {
+			return nil, f.err
+		}
+		f.count++
+		rates := coinpayments.RatesResponse{}
+		rate := rates["BTC"]
+		rate.RateBTC = coinpayments.AmountFromSatoshis(int64(f.count))
+		rates["BTC"] = rate
+		return &rates, nil
+	}
+	return f
+}
+
+func (f *ratesFake) fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.err = err
+}
+
+func btcSatoshis(t *testing.T, snapshot *coinpayments.RatesSnapshot) int64 {
+	t.Helper()
+
+	rate, ok := snapshot.Rates["BTC"]
+	if !ok {
+		t.Fatal("snapshot has no BTC rate")
+	}
+	return rate.RateBTC.Satoshis()
+}
+
+func TestRatesCacheGetBeforeRefresh(t *testing.T) {
+	cache := coinpayments.NewRatesCache(newRatesFake())
+
+	_, err := cache.Get()
+	var stale *coinpayments.StaleRatesError
+	if !errors.As(err, &stale) {
+		t.Fatalf("got error %v, want *StaleRatesError", err)
+	}
+	if !stale.FetchedAt.IsZero() || stale.LastError != nil {
+		t.Errorf("unexpected error %+v", stale)
+	}
+}
+
+func TestRatesCacheRefresh(t *testing.T) {
+	fake := newRatesFake()
+	cache := coinpayments.NewRatesCache(fake, coinpayments.WithRatesRequest(&coinpayments.RatesRequest{OmitDetails: true}))
+
+	var notified []int64
+	unsubscribe := cache.Subscribe(func(snapshot *coinpayments.RatesSnapshot) {
+		notified = append(notified, btcSatoshis(t, snapshot))
+	})
+
+	if _, err := cache.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := cache.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := btcSatoshis(t, snapshot); got != 1 {
+		t.Errorf("BTC rate = %v satoshis, want 1", got)
+	}
+	if _, ok := snapshot.Catalog().Coin("btc"); !ok {
+		t.Error("snapshot catalog has no BTC")
+	}
+
+	calls := fake.CallsTo("Rates")
+	if request := calls[0].Request.(*coinpayments.RatesRequest); !request.OmitDetails {
+		t.Errorf("rates called with %+v, want the configured request", request)
+	}
+
+	fake.fail(errors.New("api down"))
+	if _, err := cache.Refresh(); err == nil {
+		t.Fatal("expected the failed refresh to return its error")
+	}
+	if cache.LastError() == nil {
+		t.Error("LastError should hold the failed refresh")
+	}
+	if snapshot, err := cache.Get(); err != nil || btcSatoshis(t, snapshot) != 1 {
+		t.Errorf("Get after a failed refresh = %v, %v, want the previous rates", snapshot, err)
+	}
+
+	fake.fail(nil)
+	if _, err := cache.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if cache.LastError() != nil {
+		t.Errorf("LastError = %v after a successful refresh, want nil", cache.LastError())
+	}
+
+	unsubscribe()
+	if _, err := cache.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if len(notified) != 2 || notified[0] != 1 || notified[1] != 2 {
+		t.Errorf("subscriber saw %v, want [1 2]", notified)
+	}
+}
+
+func TestRatesCacheStaleness(t *testing.T) {
+	fake := newRatesFake()
+	cache := coinpayments.NewRatesCache(fake, coinpayments.WithMaxStaleness(time.Millisecond))
+
+	if _, err := cache.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	fake.fail(errors.New("api down"))
+	_, _ = cache.Refresh()
+	time.Sleep(5 * time.Millisecond)
+
+	_, err := cache.Get()
+	var stale *coinpayments.StaleRatesError
+	if !errors.As(err, &stale) {
+		t.Fatalf("got error %v, want *StaleRatesError", err)
+	}
+	if stale.FetchedAt.IsZero() || stale.MaxStaleness != time.Millisecond || stale.LastError == nil {
+		t.Errorf("unexpected error %+v", stale)
+	}
+}
+
+func TestRatesCacheStart(t *testing.T) {
+	fake := newRatesFake()
+	cache := coinpayments.NewRatesCache(fake,
+		coinpayments.WithRefreshInterval(5*time.Millisecond), coinpayments.WithMaxStaleness(time.Second))
+
+	if err := cache.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Start(); err == nil {
+		t.Error("expected an error starting the cache twice")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(fake.CallsTo("Rates")) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for background refreshes")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cache.Stop()
+
+	calls := len(fake.CallsTo("Rates"))
+	time.Sleep(20 * time.Millisecond)
+	if got := len(fake.CallsTo("Rates")); got != calls {
+		t.Errorf("rates called %v times after Stop, want %v", got, calls)
+	}
+	cache.Stop()
+}
+
+func TestRatesCacheRejectsStalenessShorterThanInterval(t *testing.T) {
+	fake := newRatesFake()
+	cache := coinpayments.NewRatesCache(fake,
+		coinpayments.WithRefreshInterval(time.Hour), coinpayments.WithMaxStaleness(time.Minute))
+
+	if err := cache.Start(); err == nil {
+		cache.Stop()
+		t.Fatal("expected an error starting with a max staleness shorter than the refresh interval")
+	}
+	if got := len(fake.CallsTo("Rates")); got != 0 {
+		t.Errorf("rates called %v times, want 0", got)
+	}
+}
+
+func TestRatesCacheInvalidOptions(t *testing.T) {
+	fake := newRatesFake()
+	cache := coinpayments.NewRatesCache(fake,
+		coinpayments.WithRefreshInterval(0), coinpayments.WithMaxStaleness(-time.Second), coinpayments.WithRatesRequest(nil))
+
+	if err := cache.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Stop()
+
+	calls := fake.CallsTo("Rates")
+	if len(calls) != 1 {
+		t.Fatalf("rates called %v times, want 1", len(calls))
+	}
+	if request := calls[0].Request.(*coinpayments.RatesRequest); request == nil || *request != (coinpayments.RatesRequest{}) {
+		t.Errorf("rates called with %+v, want an empty request", request)
+	}
+	if _, err := cache.Get(); err != nil {
+		t.Errorf("Get = %v, want the fetched rates", err)
+	}
+}
@@ -0,0 +1,215 @@
+package coinpayments
+
+import "fmt"
+
+//PaymentStatus is the status of a payment, as sent by get_tx_info and payment and deposit IPNs
+type PaymentStatus int
+
+const (
+	PaymentStatusRefunded  PaymentStatus = -2
+	PaymentStatusCancelled PaymentStatus = -1
+	PaymentStatusWaiting   PaymentStatus = 0
+	PaymentStatusConfirmed PaymentStatus = 1
+	PaymentStatusQueued    PaymentStatus = 2
+	PaymentStatusEscrow    PaymentStatus = 3
+	PaymentStatusComplete  PaymentStatus = 100
+)
+
+//IsComplete reports whether the payment has been received in full. Payments queued for the nightly payout count as
+//complete
+func (s PaymentStatus) IsComplete() bool {
+	return s >= PaymentStatusComplete || s == PaymentStatusQueued
+}
+
+//IsPending reports whether the payment may still complete
+func (s PaymentStatus) IsPending() bool {
+	return s >= 0 && !s.IsComplete()
+}
+
+//IsFailed reports whether the payment was refunded, cancelled or timed out
+func (s PaymentStatus) IsFailed() bool {
+	return s < 0
+}
+
+//IsTerminal reports whether the payment will not change status again
+func (s PaymentStatus) IsTerminal() bool {
+	return s.IsComplete() || s.IsFailed()
+}
+
+func (s PaymentStatus) String() string {
+	switch s {
+	case PaymentStatusRefunded:
+		return "refunded"
+	case PaymentStatusCancelled:
+		return "cancelled"
+	case PaymentStatusWaiting:
+		return "waiting"
+	case PaymentStatusConfirmed:
+		return "confirmed"
+	case PaymentStatusQueued:
+		return "queued"
+	case PaymentStatusEscrow:
+		return "escrow"
+	case PaymentStatusComplete:
+		return "complete"
+	}
+	return fmt.Sprintf("PaymentStatus(%d)", int(s))
+}
+
+//UnmarshalJSON decodes a json number or string
+func (s *PaymentStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalStatus(data, (*int)(s))
+}
+
+//WithdrawalStatus is the status of a withdrawal
+type WithdrawalStatus int
+
+const (
+	WithdrawalStatusCancelled     WithdrawalStatus = -1
+	WithdrawalStatusAwaitingEmail WithdrawalStatus = 0
+	WithdrawalStatusPending       WithdrawalStatus = 1
+	WithdrawalStatusComplete      WithdrawalStatus = 2
+)
+
+//IsComplete reports whether the withdrawal has been sent
+func (s WithdrawalStatus) IsComplete() bool {
+	return s == WithdrawalStatusComplete
+}
+
+//IsPending reports whether the withdrawal is waiting for email confirmation or to be sent
+func (s WithdrawalStatus) IsPending() bool {
+	return s == WithdrawalStatusAwaitingEmail || s == WithdrawalStatusPending
+}
+
+//IsFailed reports whether the withdrawal was cancelled
+func (s WithdrawalStatus) IsFailed() bool {
+	return s < 0
+}
+
+//IsTerminal reports whether the withdrawal will not change status again
+func (s WithdrawalStatus) IsTerminal() bool {
+	return s.IsComplete() || s.IsFailed()
+}
+
+func (s WithdrawalStatus) String() string {
+	switch s {
+	case WithdrawalStatusCancelled:
+		return "cancelled"
+	case WithdrawalStatusAwaitingEmail:
+		return "awaiting_email"
+	case WithdrawalStatusPending:
+		return "pending"
+	case WithdrawalStatusComplete:
+		return "complete"
+	}
+	return fmt.Sprintf("WithdrawalStatus(%d)", int(s))
+}
+
+//UnmarshalJSON decodes a json number or string
+func (s *WithdrawalStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalStatus(data, (*int)(s))
+}
+
+//TransferStatus is the status of a transfer
+type TransferStatus int
+
+const (
+	TransferStatusCancelled     TransferStatus = -1
+	TransferStatusAwaitingEmail TransferStatus = 0
+	TransferStatusCreated       TransferStatus = 1
+)
+
+//IsComplete reports whether the transfer was created without needing email confirmation
+func (s TransferStatus) IsComplete() bool {
+	return s == TransferStatusCreated
+}
+
+//IsPending reports whether the transfer is waiting for email confirmation
+func (s TransferStatus) IsPending() bool {
+	return s == TransferStatusAwaitingEmail
+}
+
+//IsFailed reports whether the transfer was cancelled
+func (s TransferStatus) IsFailed() bool {
+	return s < 0
+}
+
+//IsTerminal reports whether the transfer will not change status again
+func (s TransferStatus) IsTerminal() bool {
+	return s.IsComplete() || s.IsFailed()
+}
+
+func (s TransferStatus) String() string {
+	switch s {
+	case TransferStatusCancelled:
+		return "cancelled"
+	case TransferStatusAwaitingEmail:
+		return "awaiting_email"
+	case TransferStatusCreated:
+		return "created"
+	}
+	return fmt.Sprintf("TransferStatus(%d)", int(s))
+}
+
+//UnmarshalJSON decodes a json number or string
+func (s *TransferStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalStatus(data, (*int)(s))
+}
+
+//ConversionStatus is the status of a conversion
+type ConversionStatus int
+
+const (
+	ConversionStatusFailed     ConversionStatus = -1
+	ConversionStatusPending    ConversionStatus = 0
+	ConversionStatusProcessing ConversionStatus = 1
+	ConversionStatusComplete   ConversionStatus = 2
+)
+
+//IsComplete reports whether the converted coins have been received
+func (s ConversionStatus) IsComplete() bool {
+	return s == ConversionStatusComplete
+}
+
+//IsPending reports whether the conversion is waiting or in progress
+func (s ConversionStatus) IsPending() bool {
+	return s == ConversionStatusPending || s == ConversionStatusProcessing
+}
+
+//IsFailed reports whether the conversion failed
+func (s ConversionStatus) IsFailed() bool {
+	return s < 0
+}
+
+//IsTerminal reports whether the conversion will not change status again
+func (s ConversionStatus) IsTerminal() bool {
+	return s.IsComplete() || s.IsFailed()
+}
+
+func (s ConversionStatus) String() string {
+	switch s {
+	case ConversionStatusFailed:
+		return "failed"
+	case ConversionStatusPending:
+		return "pending"
+	case ConversionStatusProcessing:
+		return "processing"
+	case ConversionStatusComplete:
+		return "complete"
+	}
+	return fmt.Sprintf("ConversionStatus(%d)", int(s))
+}
+
+//UnmarshalJSON decodes a json number or string
+func (s *ConversionStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalStatus(data, (*int)(s))
+}
+
+func unmarshalStatus(data []byte, status *int) error {
+	var i FlexInt
+	if err := i.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	*status = int(i)
+	return nil
+}
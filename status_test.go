@@ -0,0 +1,87 @@
+package coinpayments
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+type testStatus interface {
+	fmt.Stringer
+	IsComplete() bool
+	IsPending() bool
+	IsFailed() bool
+	IsTerminal() bool
+}
+
+func TestStatusPredicates(t *testing.T) {
+	tests := []struct {
+		status   testStatus
+		want     string
+		complete bool
+		pending  bool
+		failed   bool
+	}{
+		{status: PaymentStatusRefunded, want: "refunded", failed: true},
+		{status: PaymentStatusCancelled, want: "cancelled", failed: true},
+		{status: PaymentStatusWaiting, want: "waiting", pending: true},
+		{status: PaymentStatusConfirmed, want: "confirmed", pending: true},
+		{status: PaymentStatusQueued, want: "queued", complete: true},
+		{status: PaymentStatusEscrow, want: "escrow", pending: true},
+		{status: PaymentStatusComplete, want: "complete", complete: true},
+		{status: PaymentStatus(101), want: "PaymentStatus(101)", complete: true},
+		{status: PaymentStatus(-3), want: "PaymentStatus(-3)", failed: true},
+
+		{status: WithdrawalStatusCancelled, want: "cancelled", failed: true},
+		{status: WithdrawalStatusAwaitingEmail, want: "awaiting_email", pending: true},
+		{status: WithdrawalStatusPending, want: "pending", pending: true},
+		{status: WithdrawalStatusComplete, want: "complete", complete: true},
+		{status: WithdrawalStatus(7), want: "WithdrawalStatus(7)"},
+
+		{status: TransferStatusCancelled, want: "cancelled", failed: true},
+		{status: TransferStatusAwaitingEmail, want: "awaiting_email", pending: true},
+		{status: TransferStatusCreated, want: "created", complete: true},
+		{status: TransferStatus(7), want: "TransferStatus(7)"},
+
+		{status: ConversionStatusFailed, want: "failed", failed: true},
+		{status: ConversionStatusPending, want: "pending", pending: true},
+		{status: ConversionStatusProcessing, want: "processing", pending: true},
+		{status: ConversionStatusComplete, want: "complete", complete: true},
+		{status: ConversionStatus(7), want: "ConversionStatus(7)"},
+	}
+
+	for _, test := range tests {
+		s := test.status
+		if got := s.String(); got != test.want {
+			t.Errorf("%T(%v).String() = %q, want %q", s, test.want, got, test.want)
+		}
+		if s.IsComplete() != test.complete || s.IsPending() != test.pending || s.IsFailed() != test.failed {
+			t.Errorf("%T %v: complete %v pending %v failed %v, want %v %v %v", s, test.want,
+				s.IsComplete(), s.IsPending(), s.IsFailed(), test.complete, test.pending, test.failed)
+		}
+		if s.IsTerminal() != (test.complete || test.failed) {
+			t.Errorf("%T %v: IsTerminal() = %v", s, test.want, s.IsTerminal())
+		}
+	}
+}
+
+func TestStatusUnmarshalJSON(t *testing.T) {
+	var response struct {
+		Payment    PaymentStatus    `json:"payment"`
+		Withdrawal WithdrawalStatus `json:"withdrawal"`
+		Transfer   TransferStatus   `json:"transfer"`
+		Conversion ConversionStatus `json:"conversion"`
+	}
+	if err := json.Unmarshal([]byte(`{"payment":"100","withdrawal":2,"transfer":"-1","conversion":null}`), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Payment != PaymentStatusComplete || response.Withdrawal != WithdrawalStatusComplete ||
+		response.Transfer != TransferStatusCancelled || response.Conversion != ConversionStatusPending {
+		t.Errorf("unexpected decode %+v", response)
+	}
+
+	var status PaymentStatus
+	if err := json.Unmarshal([]byte(`"complete"`), &status); err == nil {
+		t.Error("expected an error decoding a status name")
+	}
+}
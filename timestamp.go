@@ -0,0 +1,61 @@
+package coinpayments
+
+import (
+	"strconv"
+	"time"
+)
+
+//UnixTime is a time the api sends as a count of seconds since the Unix epoch, as a json number or string. 0 and
+//empty values decode to the zero time
+type UnixTime struct {
+	time.Time
+}
+
+//UnmarshalJSON decodes a json number or string of Unix seconds
+func (t *UnixTime) UnmarshalJSON(data []byte) error {
+	var i FlexInt
+	if err := i.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	*t = UnixTime{Time: unixTime(int64(i))}
+	return nil
+}
+
+//MarshalJSON encodes the time as a json number of Unix seconds, or 0 for the zero time
+func (t UnixTime) MarshalJSON() ([]byte, error) {
+	return []byte(formatUnixTime(t.Time)), nil
+}
+
+//DurationSeconds is a duration the api sends as a count of seconds, as a json number or string
+type DurationSeconds struct {
+	time.Duration
+}
+
+//UnmarshalJSON decodes a json number or string of seconds
+func (d *DurationSeconds) UnmarshalJSON(data []byte) error {
+	var i FlexInt
+	if err := i.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	*d = DurationSeconds{Duration: time.Duration(i) * time.Second}
+	return nil
+}
+
+//MarshalJSON encodes the duration as a json number of whole seconds
+func (d DurationSeconds) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(d.Duration/time.Second), 10)), nil
+}
+
+func unixTime(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+func formatUnixTime(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.Unix(), 10)
+}
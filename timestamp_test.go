@@ -0,0 +1,127 @@
+package coinpayments
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestUnixTimeUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		zero    bool
+		wantErr bool
+	}{
+		{in: `1600000000`, want: 1600000000},
+		{in: `"1600000000"`, want: 1600000000},
+		{in: `0`, zero: true},
+		{in: `"0"`, zero: true},
+		{in: `""`, zero: true},
+		{in: `null`, zero: true},
+		{in: `"2020-09-13"`, wantErr: true},
+		{in: `1.5`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		var u UnixTime
+		err := json.Unmarshal([]byte(test.in), &u)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("decoding %v = %v, want an error", test.in, u)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("decoding %v returned error %v", test.in, err)
+			continue
+		}
+		if test.zero {
+			if !u.IsZero() {
+				t.Errorf("decoding %v = %v, want the zero time", test.in, u)
+			}
+			continue
+		}
+		if u.Unix() != test.want {
+			t.Errorf("decoding %v = %v, want %v", test.in, u.Unix(), test.want)
+		}
+	}
+}
+
+func TestUnixTimeMarshalJSON(t *testing.T) {
+	tests := []struct {
+		in   UnixTime
+		want string
+	}{
+		{in: UnixTime{Time: time.Unix(1600000000, 0)}, want: "1600000000"},
+		{in: UnixTime{Time: time.Unix(1600000000, 999999999)}, want: "1600000000"},
+		{in: UnixTime{}, want: "0"},
+	}
+
+	for _, test := range tests {
+		data, err := json.Marshal(test.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.want {
+			t.Errorf("encoding %v = %s, want %v", test.in, data, test.want)
+		}
+	}
+}
+
+func TestDurationSeconds(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: `7200`, want: 2 * time.Hour},
+		{in: `"900"`, want: 15 * time.Minute},
+		{in: `null`, want: 0},
+		{in: `"soon"`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		var d DurationSeconds
+		err := json.Unmarshal([]byte(test.in), &d)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("decoding %v = %v, want an error", test.in, d)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("decoding %v returned error %v", test.in, err)
+			continue
+		}
+		if d.Duration != test.want {
+			t.Errorf("decoding %v = %v, want %v", test.in, d.Duration, test.want)
+		}
+	}
+
+	data, err := json.Marshal(DurationSeconds{Duration: 90*time.Second + 500*time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "90" {
+		t.Errorf("encoding 90.5s = %s, want 90", data)
+	}
+}
+
+func TestTimestampResponseFields(t *testing.T) {
+	var info GetTxInfoResponse
+	if err := json.Unmarshal([]byte(`{"time_created":1600000000,"time_expires":"1600007200"}`), &info); err != nil {
+		t.Fatal(err)
+	}
+	if got := info.TimeExpires.Sub(info.TimeCreated.Time); got != 2*time.Hour {
+		t.Errorf("TimeExpires - TimeCreated = %v, want 2h", got)
+	}
+
+	var created CreateTransactionResponse
+	if err := json.Unmarshal([]byte(`{"timeout":"9000"}`), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Timeout.Duration != 150*time.Minute {
+		t.Errorf("Timeout = %v, want 2h30m", created.Timeout.Duration)
+	}
+}
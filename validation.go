@@ -0,0 +1,118 @@
+package coinpayments
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+//FieldError describes a single field that failed validation
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Field, e.Message)
+}
+
+//ValidationError is returned when one or more fields fail validation, listing every failure
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("coinpayments: validation failed - %v", strings.Join(messages, "; "))
+}
+
+type validator struct {
+	errors []*FieldError
+}
+
+func (v *validator) add(field, format string, args ...interface{}) {
+	v.errors = append(v.errors, &FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errors}
+}
+
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+	}
+}
+
+func (v *validator) positive(field string, amount Amount) {
+	if amount.Sign() <= 0 {
+		v.add(field, "must be greater than 0")
+	}
+}
+
+func (v *validator) exactlyOne(field1, value1, field2, value2 string) {
+	switch {
+	case value1 == "" && value2 == "":
+		v.add(field1, "%v or %v is required", field1, field2)
+	case value1 != "" && value2 != "":
+		v.add(field1, "cannot be set together with %v", field2)
+	}
+}
+
+func (v *validator) between(field string, value, min, max int) {
+	if value < min || value > max {
+		v.add(field, "must be between %d and %d", min, max)
+	}
+}
+
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	if value != "" && !containsString(allowed, value) {
+		v.add(field, "must be one of %v", strings.Join(allowed, ", "))
+	}
+}
+
+func (v *validator) integer(field, value string) {
+	if value == "" {
+		return
+	}
+	if n, err := strconv.Atoi(value); err != nil || n < 0 {
+		v.add(field, "%q is not a non-negative integer", value)
+	}
+}
+
+func (v *validator) email(field, value string) {
+	if value == "" {
+		return
+	}
+	if at := strings.LastIndex(value, "@"); at < 1 || at == len(value)-1 || strings.ContainsAny(value, " \t\r\n") {
+		v.add(field, "%q is not an email address", value)
+	}
+}
+
+func (v *validator) url(field, value string) {
+	if value == "" {
+		return
+	}
+	if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add(field, "%q is not an http or https url", value)
+	}
+}
+
+func (v *validator) currency(field string, c Currency, required bool) {
+	if c == "" {
+		if required {
+			v.add(field, "is required")
+		}
+		return
+	}
+	if _, err := ParseCurrency(string(c)); err != nil {
+		v.add(field, "%q is not a currency code", c)
+	}
+}
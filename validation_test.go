@@ -0,0 +1,167 @@
+package coinpayments
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func validationMessages(err error) string {
+	if err == nil {
+		return ""
+	}
+	validation, ok := err.(*ValidationError)
+	if !ok {
+		return "not a *ValidationError: " + err.Error()
+	}
+	messages := make([]string, len(validation.Errors))
+	for i, fieldErr := range validation.Errors {
+		messages[i] = fieldErr.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func TestValidatorRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule func(v *validator)
+		want string
+	}{
+		{name: "required", rule: func(v *validator) { v.required("F", "x") }},
+		{name: "required blank", rule: func(v *validator) { v.required("F", " ") }, want: "F: is required"},
+		{name: "positive", rule: func(v *validator) { v.positive("F", MustParseAmount("0.00000001")) }},
+		{name: "positive zero", rule: func(v *validator) { v.positive("F", Amount{}) }, want: "F: must be greater than 0"},
+		{name: "positive negative", rule: func(v *validator) { v.positive("F", MustParseAmount("-1")) }, want: "F: must be greater than 0"},
+		{name: "exactlyOne first", rule: func(v *validator) { v.exactlyOne("A", "a", "B", "") }},
+		{name: "exactlyOne second", rule: func(v *validator) { v.exactlyOne("A", "", "B", "b") }},
+		{name: "exactlyOne neither", rule: func(v *validator) { v.exactlyOne("A", "", "B", "") }, want: "A: A or B is required"},
+		{name: "exactlyOne both", rule: func(v *validator) { v.exactlyOne("A", "a", "B", "b") }, want: "A: cannot be set together with B"},
+		{name: "between", rule: func(v *validator) { v.between("F", 100, 0, 100) }},
+		{name: "between above", rule: func(v *validator) { v.between("F", 101, 0, 100) }, want: "F: must be between 0 and 100"},
+		{name: "between below", rule: func(v *validator) { v.between("F", -1, 0, 100) }, want: "F: must be between 0 and 100"},
+		{name: "oneOf", rule: func(v *validator) { v.oneOf("F", "1", "0", "1") }},
+		{name: "oneOf empty", rule: func(v *validator) { v.oneOf("F", "", "0", "1") }},
+		{name: "oneOf other", rule: func(v *validator) { v.oneOf("F", "2", "0", "1") }, want: "F: must be one of 0, 1"},
+		{name: "integer", rule: func(v *validator) { v.integer("F", "25") }},
+		{name: "integer negative", rule: func(v *validator) { v.integer("F", "-1") }, want: `F: "-1" is not a non-negative integer`},
+		{name: "integer text", rule: func(v *validator) { v.integer("F", "ten") }, want: `F: "ten" is not a non-negative integer`},
+		{name: "email", rule: func(v *validator) { v.email("F", "buyer@example.com") }},
+		{name: "email without at", rule: func(v *validator) { v.email("F", "buyer") }, want: `F: "buyer" is not an email address`},
+		{name: "email without domain", rule: func(v *validator) { v.email("F", "buyer@") }, want: `F: "buyer@" is not an email address`},
+		{name: "email with space", rule: func(v *validator) { v.email("F", "a b@c.d") }, want: `F: "a b@c.d" is not an email address`},
+		{name: "url", rule: func(v *validator) { v.url("F", "https://example.com/ipn") }},
+		{name: "url scheme", rule: func(v *validator) { v.url("F", "ftp://example.com") }, want: `F: "ftp://example.com" is not an http or https url`},
+		{name: "url relative", rule: func(v *validator) { v.url("F", "/ipn") }, want: `F: "/ipn" is not an http or https url`},
+		{name: "currency", rule: func(v *validator) { v.currency("F", "BTC", true) }},
+		{name: "currency optional", rule: func(v *validator) { v.currency("F", "", false) }},
+		{name: "currency required", rule: func(v *validator) { v.currency("F", "", true) }, want: "F: is required"},
+		{name: "currency invalid", rule: func(v *validator) { v.currency("F", "B C", false) }, want: `F: "B C" is not a currency code`},
+	}
+
+	for _, test := range tests {
+		v := &validator{}
+		test.rule(v)
+		if got := validationMessages(v.err()); got != test.want {
+			t.Errorf("%v: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request callable
+		want    string
+	}{
+		{
+			name: "valid transaction",
+			request: &CreateTransactionRequest{Amount: MustParseAmount("10"), Currency1: "USD", Currency2: "BTC",
+				BuyerEmail: "buyer@example.com", IPNURL: "https://example.com/ipn"},
+		},
+		{
+			name:    "empty transaction",
+			request: &CreateTransactionRequest{},
+			want:    "Amount: must be greater than 0; Currency1: is required; Currency2: is required; BuyerEmail: is required",
+		},
+		{
+			name: "withdrawal to an address and a tag",
+			request: &CreateWithdrawalRequest{Amount: MustParseAmount("1"), Currency: "BTC", Address: "addr",
+				PBNTag: "$tag"},
+			want: "Address: cannot be set together with PBNTag",
+		},
+		{
+			name:    "withdrawal without a destination",
+			request: &CreateWithdrawalRequest{Amount: MustParseAmount("1"), Currency: "BTC", DestTag: "7", AutoConfirm: "yes"},
+			want:    "Address: Address or PBNTag is required; DestTag: requires Address; AutoConfirm: must be one of 0, 1",
+		},
+		{
+			name:    "transfer without a destination",
+			request: &CreateTransferRequest{Amount: MustParseAmount("1"), Currency: "BTC"},
+			want:    "Merchant: Merchant or PBNTag is required",
+		},
+		{
+			name:    "conversion to the same coin",
+			request: &ConvertRequest{Amount: MustParseAmount("1"), From: "btc", To: "BTC"},
+			want:    "To: must differ from From",
+		},
+		{
+			name:    "too many results",
+			request: &GetTxIdsRequest{MaxResults: 101, Offset: -1, Limit: "x"},
+			want:    `MaxResults: must be between 0 and 100; Offset: cannot be negative; Limit: "x" is not a non-negative integer`,
+		},
+		{
+			name:    "malformed newer",
+			request: &GetWithdrawalHistoryRequest{Newer: "yesterday"},
+			want:    `Newer: "yesterday" is not a non-negative integer`,
+		},
+		{
+			name:    "too many txids",
+			request: &GetTxInfoMultiRequest{TXID: strings.Repeat("CP|", 25) + "CP"},
+			want:    "TXID: holds 26 ids, at most 25 are allowed",
+		},
+		{
+			name:    "unknown acceptance",
+			request: &RatesRequest{Acceptance: 3},
+			want:    "Acceptance: 3 is not a RatesAcceptance",
+		},
+		{
+			name:    "pbn tag update",
+			request: &UpdatePBNTagRequest{Email: "nobody", URL: "example.com"},
+			want:    `TagID: is required; Email: "nobody" is not an email address; URL: "example.com" is not an http or https url`,
+		},
+	}
+
+	for _, test := range tests {
+		if got := validationMessages(test.request.Validate()); got != test.want {
+			t.Errorf("%v: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := (&ConvertRequest{}).Validate()
+	want := "coinpayments: validation failed - Amount: must be greater than 0; From: is required; To: is required"
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v, want %v", err, want)
+	}
+}
+
+func TestCallValidatesBeforeSending(t *testing.T) {
+	sent := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.Write([]byte(`{"error":"ok","result":{}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("public", "private", WithAPIURL(server.URL))
+	if _, err := client.GetTxInfo(&GetTxInfoRequest{}); err == nil {
+		t.Error("expected a validation error")
+	} else if _, ok := err.(*ValidationError); !ok {
+		t.Errorf("got error %v, want *ValidationError", err)
+	}
+	if sent != 0 {
+		t.Errorf("sent %v requests, want none", sent)
+	}
+}